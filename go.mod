@@ -8,11 +8,11 @@ require (
 	github.com/go-resty/resty/v2 v2.16.5
 	github.com/stretchr/testify v1.11.1
 	golang.org/x/oauth2 v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/net v0.38.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
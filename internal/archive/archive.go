@@ -0,0 +1,143 @@
+// Package archive writes local copies of Reddit things, so that they can be
+// kept after they are shredded.
+package archive
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is the kind of thing that a Record is a copy of.
+type Kind string
+
+const (
+	KindComment Kind = "comment"
+	KindPost    Kind = "post"
+	KindMessage Kind = "message"
+)
+
+// Record is an archived copy of a single thing.
+type Record struct {
+	Kind      Kind      `json:"kind"`
+	Fullname  string    `json:"fullname"`
+	Subreddit string    `json:"subreddit"`
+	Title     string    `json:"title,omitempty"`
+	Body      string    `json:"body,omitempty"`
+	Score     int       `json:"score"`
+	Permalink string    `json:"permalink"`
+	Created   time.Time `json:"created"`
+}
+
+// csvHeader is the header row of CSV archives. The columns are in the same
+// order as the fields of Record.
+var csvHeader = []string{"kind", "fullname", "subreddit", "title", "body", "score", "permalink", "created"}
+
+// Writer writes records to an archive. Each record is written through to the
+// underlying file before Write returns, so that nothing is lost if the process
+// exits before the Writer is closed.
+type Writer interface {
+	Write(rec Record) error
+	Close() error
+}
+
+// Open opens the archive file at the given path for writing, creating it if
+// necessary. Records are appended to existing archives. The format is chosen
+// by the file's extension: ".jsonl" (or ".ndjson") for JSON Lines, or ".csv"
+// for CSV.
+func Open(path string) (Writer, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	switch ext {
+	case ".jsonl", ".ndjson", ".csv":
+	default:
+		return nil, fmt.Errorf("unsupported archive format %q (must be .jsonl, .ndjson or .csv)", ext)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening archive: %w", err)
+	}
+	if ext != ".csv" {
+		return &jsonWriter{f: f, enc: json.NewEncoder(f)}, nil
+	}
+	w := &csvWriter{f: f, w: csv.NewWriter(f)}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("error opening archive: %w", err)
+	}
+	// Only write the header to new files, not ones being appended to.
+	if info.Size() == 0 {
+		if err := w.writeRow(csvHeader); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// jsonWriter writes records as JSON Lines, i.e. one JSON object per line.
+type jsonWriter struct {
+	mu  sync.Mutex
+	f   io.Closer
+	enc *json.Encoder
+}
+
+func (w *jsonWriter) Write(rec Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.enc.Encode(rec); err != nil {
+		return fmt.Errorf("error writing archive record: %w", err)
+	}
+	return nil
+}
+
+func (w *jsonWriter) Close() error {
+	return w.f.Close()
+}
+
+// csvWriter writes records as CSV, with a header row.
+type csvWriter struct {
+	mu sync.Mutex
+	f  io.Closer
+	w  *csv.Writer
+}
+
+func (w *csvWriter) Write(rec Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writeRow(
+		[]string{
+			string(rec.Kind),
+			rec.Fullname,
+			rec.Subreddit,
+			rec.Title,
+			rec.Body,
+			strconv.Itoa(rec.Score),
+			rec.Permalink,
+			rec.Created.UTC().Format(time.RFC3339),
+		},
+	)
+}
+
+// writeRow writes a row and flushes it to the file.
+func (w *csvWriter) writeRow(row []string) error {
+	if err := w.w.Write(row); err != nil {
+		return fmt.Errorf("error writing archive record: %w", err)
+	}
+	w.w.Flush()
+	if err := w.w.Error(); err != nil {
+		return fmt.Errorf("error writing archive record: %w", err)
+	}
+	return nil
+}
+
+func (w *csvWriter) Close() error {
+	return w.f.Close()
+}
@@ -0,0 +1,69 @@
+package archive
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testRecord = Record{
+	Kind:      KindComment,
+	Fullname:  "t1_abc",
+	Subreddit: "golang",
+	Body:      "Hello,\nworld",
+	Score:     42,
+	Permalink: "/r/golang/comments/xyz/title/abc/",
+	Created:   time.Date(2024, 10, 30, 18, 4, 5, 0, time.UTC),
+}
+
+func TestOpen_JSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.jsonl")
+	w, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(testRecord))
+	require.NoError(t, w.Close())
+
+	// Records are appended when reopened.
+	w, err = Open(path)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(testRecord))
+	require.NoError(t, w.Close())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 2)
+	var rec Record
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &rec))
+	require.Equal(t, testRecord, rec)
+}
+
+func TestOpen_CSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.csv")
+	for range 2 {
+		w, err := Open(path)
+		require.NoError(t, err)
+		require.NoError(t, w.Write(testRecord))
+		require.NoError(t, w.Close())
+	}
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		"kind,fullname,subreddit,title,body,score,permalink,created\n"+
+			"comment,t1_abc,golang,,\"Hello,\nworld\",42,/r/golang/comments/xyz/title/abc/,2024-10-30T18:04:05Z\n"+
+			"comment,t1_abc,golang,,\"Hello,\nworld\",42,/r/golang/comments/xyz/title/abc/,2024-10-30T18:04:05Z\n",
+		string(b),
+	)
+}
+
+func TestOpen_UnsupportedFormat(t *testing.T) {
+	_, err := Open(filepath.Join(t.TempDir(), "archive.txt"))
+	require.ErrorContains(t, err, `unsupported archive format ".txt"`)
+}
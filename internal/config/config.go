@@ -0,0 +1,351 @@
+// Package config loads shreddit's YAML configuration files, which can set any
+// command-line flag, reference the Reddit credentials to use, and declare an
+// ordered list of rules for what to shred.
+//
+// An example:
+//
+//	credentials:
+//	  username: me
+//	  password: {env: REDDIT_PASSWORD}
+//	  client-id: abc123
+//	  client-secret: {file: ~/.config/shreddit/client-secret}
+//	dry-run: true
+//	rules:
+//	  - name: old golang comments
+//	    types: [comments]
+//	    subreddits: [golang]
+//	    max-days: 365
+//	  - types: [posts]
+//	    max-days: 30
+//	    max-score: 9
+//	profiles:
+//	  alt:
+//	    credentials:
+//	      username: my-alt
+//	      password: {env: REDDIT_ALT_PASSWORD}
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/ccampo133/shreddit-go/internal/shred"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the contents of a config file.
+type File struct {
+	Credentials Credentials `yaml:"credentials"`
+	Rules       Rules       `yaml:"rules"`
+	// Profiles are named Reddit accounts, which are shredded instead of the
+	// account given by Credentials when selected.
+	Profiles map[string]Profile `yaml:"profiles"`
+	// Flags holds every other top-level key, which set the command-line flag
+	// of the same name, e.g. "dry-run" or "user-agent".
+	Flags map[string]any `yaml:",inline"`
+}
+
+// Profile is a named Reddit account with its own credentials and rules.
+type Profile struct {
+	// Credentials which are unset fall back to those of the File (or the
+	// equivalent flags), e.g. so that accounts can share a client ID.
+	Credentials Credentials `yaml:"credentials"`
+	// TokenFile overrides the token file flag, if set. Otherwise, the
+	// profile's name is added to the flag's path.
+	TokenFile string `yaml:"token-file"`
+	// UserAgent overrides the user agent flag, if set.
+	UserAgent string `yaml:"user-agent"`
+	// Rules override those of the File, if set.
+	Rules Rules `yaml:"rules"`
+}
+
+// ProfileNames returns the names of the profiles in alphabetical order.
+func (f *File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Credentials references the credentials used to log in to Reddit.
+type Credentials struct {
+	Username     Secret `yaml:"username"`
+	Password     Secret `yaml:"password"`
+	ClientID     Secret `yaml:"client-id"`
+	ClientSecret Secret `yaml:"client-secret"`
+	TOTPSecret   Secret `yaml:"totp-secret"`
+}
+
+// flags returns the credentials keyed by the names of their flags.
+func (c Credentials) flags() map[string]Secret {
+	return map[string]Secret{
+		"username":      c.Username,
+		"password":      c.Password,
+		"client-id":     c.ClientID,
+		"client-secret": c.ClientSecret,
+		"totp-secret":   c.TOTPSecret,
+	}
+}
+
+// Secret is a credential, which is either given inline as a string, or read
+// from an environment variable ({env: NAME}) or a file ({file: PATH}), so that
+// it doesn't have to be kept in the config file itself.
+type Secret struct {
+	Value string
+	Env   string `yaml:"env"`
+	File  string `yaml:"file"`
+}
+
+func (s *Secret) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&s.Value)
+	}
+	type secret Secret // Avoid recursing into this method.
+	if err := node.Decode((*secret)(s)); err != nil {
+		return err
+	}
+	if s.Env != "" && s.File != "" {
+		return fmt.Errorf("line %d: only one of env and file may be set", node.Line)
+	}
+	return nil
+}
+
+// IsZero reports whether the secret is unset.
+func (s Secret) IsZero() bool {
+	return s == Secret{}
+}
+
+// Resolve returns the value of the secret. Values read from files have
+// surrounding whitespace trimmed.
+func (s Secret) Resolve() (string, error) {
+	switch {
+	case s.Env != "":
+		value, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", s.Env)
+		}
+		return value, nil
+	case s.File != "":
+		b, err := os.ReadFile(kong.ExpandPath(s.File))
+		if err != nil {
+			return "", fmt.Errorf("error reading secret: %w", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	default:
+		return s.Value, nil
+	}
+}
+
+// ResolveOr returns the value of the secret, or fallback if it is unset.
+func (s Secret) ResolveOr(fallback string) (string, error) {
+	if s.IsZero() {
+		return fallback, nil
+	}
+	return s.Resolve()
+}
+
+// Rule describes a set of things to shred. Every criterion which is set must
+// match for the rule to match.
+type Rule struct {
+	// Name identifies the rule in logs. Defaults to its position in the list.
+	Name string `yaml:"name"`
+	// Types are the thing types that the rule applies to. Required.
+	Types []string `yaml:"types"`
+	// Subreddits, if set, are glob patterns of the only subreddits that the
+	// rule applies to.
+	Subreddits []string `yaml:"subreddits"`
+	// KeepSubreddits are glob patterns of subreddits that the rule never
+	// applies to.
+	KeepSubreddits []string `yaml:"keep-subreddits"`
+	// MaxDays, if set, only matches things older than this many days.
+	MaxDays *int `yaml:"max-days"`
+	// Before, if set, only matches things created before this time. Takes
+	// precedence over MaxDays.
+	Before time.Time `yaml:"before"`
+	// MaxScore, if set, only matches things with a score of at most this.
+	MaxScore *int `yaml:"max-score"`
+	// IncludePatterns, if set, only matches comments and posts whose text
+	// matches at least one of these regular expressions.
+	IncludePatterns []string `yaml:"include-patterns"`
+	// ExcludePatterns never matches comments and posts whose text matches any
+	// of these regular expressions.
+	ExcludePatterns []string `yaml:"exclude-patterns"`
+}
+
+// ThingTypes returns the thing types that the rule applies to.
+func (r Rule) ThingTypes() ([]shred.ThingType, error) {
+	if len(r.Types) == 0 {
+		return nil, errors.New("types must be set")
+	}
+	return shred.ParseThingTypes(r.Types)
+}
+
+// Filter returns a filter which allows the things matched by the rule to be
+// shredded, and keeps everything else. now is used as the reference time for
+// MaxDays.
+func (r Rule) Filter(now time.Time) (shred.Filter, error) {
+	thingTypes, err := r.ThingTypes()
+	if err != nil {
+		return nil, err
+	}
+	filters := []shred.Filter{shred.TypeFilter{Types: thingTypes}}
+	switch {
+	case !r.Before.IsZero():
+		filters = append(filters, shred.AgeFilter{Before: r.Before})
+	case r.MaxDays != nil:
+		filters = append(filters, shred.AgeFilter{Before: now.AddDate(0, 0, -*r.MaxDays)})
+	}
+	if r.MaxScore != nil {
+		filters = append(filters, shred.ScoreFilter{Max: *r.MaxScore})
+	}
+	// As with the equivalent flags, subreddit patterns don't apply to messages
+	// and friends, which aren't in any subreddit.
+	noSubreddit := shred.Not(shred.TypeFilter{Types: shred.SubredditThingTypes})
+	if len(r.Subreddits) > 0 {
+		if err := shred.ValidateSubredditPatterns(r.Subreddits); err != nil {
+			return nil, err
+		}
+		filters = append(filters, shred.Any(noSubreddit, shred.SubredditFilter{Patterns: r.Subreddits}))
+	}
+	if len(r.KeepSubreddits) > 0 {
+		if err := shred.ValidateSubredditPatterns(r.KeepSubreddits); err != nil {
+			return nil, err
+		}
+		filters = append(filters, shred.Any(noSubreddit, shred.Not(shred.SubredditFilter{Patterns: r.KeepSubreddits})))
+	}
+	// As with the equivalent flags, content patterns only apply to comments
+	// and posts.
+	notOwn := shred.Not(shred.TypeFilter{Types: []shred.ThingType{shred.ThingTypeComments, shred.ThingTypePosts}})
+	if len(r.IncludePatterns) > 0 {
+		patterns, err := shred.CompilePatterns(r.IncludePatterns)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, shred.Any(notOwn, shred.RegexFilter{Patterns: patterns}))
+	}
+	if len(r.ExcludePatterns) > 0 {
+		patterns, err := shred.CompilePatterns(r.ExcludePatterns)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, shred.Any(notOwn, shred.Not(shred.RegexFilter{Patterns: patterns})))
+	}
+	return shred.All(filters...), nil
+}
+
+// Rules are evaluated in order, and a thing is shredded if any of them matches
+// it.
+type Rules []Rule
+
+// Filter returns a filter which allows a thing to be shredded if any of the
+// rules matches it, along with the union of the thing types that the rules
+// apply to. The reasons for its decisions are prefixed with the names of the
+// rules.
+func (rules Rules) Filter(now time.Time) (shred.Filter, []shred.ThingType, error) {
+	filters := make([]shred.Filter, 0, len(rules))
+	var thingTypes []shred.ThingType
+	for i, rule := range rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("rule %d", i+1)
+		}
+		filter, err := rule.Filter(now)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid rule %q: %w", name, err)
+		}
+		filters = append(
+			filters, shred.FilterFunc(
+				func(item shred.Item) shred.Decision {
+					d := filter.Filter(item)
+					d.Reason = fmt.Sprintf("%s: %s", name, d.Reason)
+					return d
+				},
+			),
+		)
+		types, _ := rule.ThingTypes() // Already validated by rule.Filter.
+		for _, t := range types {
+			if !slices.Contains(thingTypes, t) {
+				thingTypes = append(thingTypes, t)
+			}
+		}
+	}
+	return shred.Any(filters...), thingTypes, nil
+}
+
+// Load reads the config file at the given path.
+func Load(path string) (*File, error) {
+	b, err := os.ReadFile(kong.ExpandPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+	return parse(bytes.NewReader(b))
+}
+
+func parse(r io.Reader) (*File, error) {
+	var f File
+	if err := yaml.NewDecoder(r).Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("error parsing config file: %w", err)
+	}
+	return &f, nil
+}
+
+// Resolver is a kong.ConfigurationLoader which resolves flags from a config
+// file. Flags given on the command line or by their environment variables take
+// precedence over the file.
+func Resolver(r io.Reader) (kong.Resolver, error) {
+	f, err := parse(r)
+	if err != nil {
+		return nil, err
+	}
+	return &resolver{file: f}, nil
+}
+
+type resolver struct {
+	file *File
+}
+
+// Validate rejects keys which don't correspond to any flag, which are most
+// likely typos.
+func (r *resolver) Validate(app *kong.Application) error {
+	var names []string
+	for _, flags := range app.AllFlags(true) {
+		for _, flag := range flags {
+			names = append(names, flag.Name)
+		}
+	}
+	for key := range r.file.Flags {
+		if !slices.Contains(names, key) {
+			return fmt.Errorf("unknown config file key %q", key)
+		}
+	}
+	return nil
+}
+
+func (r *resolver) Resolve(_ *kong.Context, _ *kong.Path, flag *kong.Flag) (any, error) {
+	for _, env := range flag.Envs {
+		if _, ok := os.LookupEnv(env); ok {
+			return nil, nil
+		}
+	}
+	if secret, ok := r.file.Credentials.flags()[flag.Name]; ok && !secret.IsZero() {
+		return secret.Resolve()
+	}
+	value, ok := r.file.Flags[flag.Name]
+	if !ok {
+		return nil, nil
+	}
+	// YAML parses unquoted timestamps, but kong expects them as strings.
+	if t, ok := value.(time.Time); ok {
+		return t.Format(time.RFC3339), nil
+	}
+	return value, nil
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/ccampo133/shreddit-go/internal/shred"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+credentials:
+  username: me
+  password: {env: TEST_SHREDDIT_PASSWORD}
+dry-run: true
+sleep: 5s
+before: 2024-01-01
+rules:
+  - name: old golang comments
+    types: [comments]
+    subreddits: [golang]
+    max-days: 365
+  - types: [posts]
+    max-days: 30
+    max-score: 9
+  - types: [saved-comments, saved-posts]
+    max-days: 7
+`
+
+func TestFile_Filter(t *testing.T) {
+	f, err := parse(strings.NewReader(testConfig))
+	require.NoError(t, err)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter, thingTypes, err := f.Rules.Filter(now)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		[]shred.ThingType{shred.ThingTypeComments, shred.ThingTypePosts, shred.ThingTypeSavedComments, shred.ThingTypeSavedPosts},
+		thingTypes,
+	)
+
+	tests := []struct {
+		name  string
+		item  shred.Item
+		shred bool
+	}{
+		{
+			name:  "old golang comment",
+			item:  shred.Item{Type: shred.ThingTypeComments, Subreddit: "golang", Created: now.AddDate(-2, 0, 0)},
+			shred: true,
+		},
+		{
+			name: "new golang comment",
+			item: shred.Item{Type: shred.ThingTypeComments, Subreddit: "golang", Created: now.AddDate(0, -1, 0)},
+		},
+		{
+			name: "old python comment",
+			item: shred.Item{Type: shred.ThingTypeComments, Subreddit: "python", Created: now.AddDate(-2, 0, 0)},
+		},
+		{
+			name:  "old low scoring post",
+			item:  shred.Item{Type: shred.ThingTypePosts, Subreddit: "python", Score: 3, Created: now.AddDate(0, -2, 0)},
+			shred: true,
+		},
+		{
+			name: "old high scoring post",
+			item: shred.Item{Type: shred.ThingTypePosts, Score: 10, Created: now.AddDate(0, -2, 0)},
+		},
+		{
+			name:  "old saved post",
+			item:  shred.Item{Type: shred.ThingTypeSavedPosts, Created: now.AddDate(0, 0, -8)},
+			shred: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				d := filter.Filter(tt.item)
+				require.Equal(t, tt.shred, !d.Keep, d.Reason)
+			},
+		)
+	}
+
+	d := filter.Filter(tests[0].item)
+	require.Equal(
+		t,
+		`old golang comments: type is comments; created before cutoff; subreddit "golang" matches "golang"`,
+		d.Reason,
+	)
+}
+
+func TestRule_Filter_NoSubreddit(t *testing.T) {
+	rule := Rule{Types: []string{"comments", "messages"}, Subreddits: []string{"golang"}}
+	filter, err := rule.Filter(time.Now())
+	require.NoError(t, err)
+	// Messages aren't in any subreddit, so subreddit patterns don't keep them.
+	require.False(t, filter.Filter(shred.Item{Type: shred.ThingTypeMessages}).Keep)
+	require.True(t, filter.Filter(shred.Item{Type: shred.ThingTypeComments, Subreddit: "python"}).Keep)
+}
+
+func TestFile_Filter_Invalid(t *testing.T) {
+	rules := Rules{{Subreddits: []string{"golang"}}}
+	_, _, err := rules.Filter(time.Now())
+	require.ErrorContains(t, err, `invalid rule "rule 1": types must be set`)
+
+	rules = Rules{{Types: []string{"comments"}, Subreddits: []string{"[golang"}}}
+	_, _, err = rules.Filter(time.Now())
+	require.ErrorContains(t, err, `invalid subreddit pattern "[golang"`)
+}
+
+func TestSecret_Resolve(t *testing.T) {
+	t.Setenv("TEST_SHREDDIT_SECRET", "from-env")
+	path := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+
+	value, err := Secret{Value: "inline"}.Resolve()
+	require.NoError(t, err)
+	require.Equal(t, "inline", value)
+
+	value, err = Secret{Env: "TEST_SHREDDIT_SECRET"}.Resolve()
+	require.NoError(t, err)
+	require.Equal(t, "from-env", value)
+
+	value, err = Secret{File: path}.Resolve()
+	require.NoError(t, err)
+	require.Equal(t, "from-file", value)
+
+	_, err = Secret{Env: "TEST_SHREDDIT_UNSET"}.Resolve()
+	require.ErrorContains(t, err, "environment variable TEST_SHREDDIT_UNSET is not set")
+
+	_, err = parse(strings.NewReader("credentials:\n  password: {env: A, file: B}\n"))
+	require.ErrorContains(t, err, "only one of env and file may be set")
+}
+
+type testCLI struct {
+	Config   kong.ConfigFlag
+	Username string        `required:""`
+	Password string        `required:""`
+	DryRun   bool          `env:"TEST_SHREDDIT_DRY_RUN"`
+	Sleep    time.Duration `default:"2s"`
+	Before   time.Time
+}
+
+func parseTestCLI(t *testing.T, args ...string) (*testCLI, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testConfig), 0o600))
+	var cli testCLI
+	parser, err := kong.New(&cli, kong.Configuration(Resolver))
+	require.NoError(t, err)
+	_, err = parser.Parse(append([]string{"--config", path}, args...))
+	return &cli, err
+}
+
+func TestResolver(t *testing.T) {
+	t.Setenv("TEST_SHREDDIT_PASSWORD", "hunter2")
+	cli, err := parseTestCLI(t)
+	require.NoError(t, err)
+	require.Equal(t, "me", cli.Username)
+	require.Equal(t, "hunter2", cli.Password)
+	require.True(t, cli.DryRun)
+	require.Equal(t, 5*time.Second, cli.Sleep)
+	require.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), cli.Before.UTC())
+
+	// Flags and environment variables take precedence over the file.
+	t.Setenv("TEST_SHREDDIT_DRY_RUN", "false")
+	cli, err = parseTestCLI(t, "--username", "you", "--sleep", "1s")
+	require.NoError(t, err)
+	require.Equal(t, "you", cli.Username)
+	require.Equal(t, time.Second, cli.Sleep)
+	require.False(t, cli.DryRun)
+}
+
+func TestResolver_UnknownKey(t *testing.T) {
+	var cli struct {
+		Config kong.ConfigFlag
+		Sleep  time.Duration
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("slep: 1s\n"), 0o600))
+	parser, err := kong.New(&cli, kong.Configuration(Resolver))
+	require.NoError(t, err)
+	_, err = parser.Parse([]string{"--config", path})
+	require.ErrorContains(t, err, `unknown config file key "slep"`)
+}
+
+func TestFile_Profiles(t *testing.T) {
+	f, err := parse(
+		strings.NewReader(
+			`
+credentials:
+  client-id: shared
+profiles:
+  personal:
+    credentials: {username: me}
+  alt:
+    credentials: {username: my-alt, client-id: other}
+    user-agent: alt-agent
+    rules:
+      - types: [comments]
+`,
+		),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"alt", "personal"}, f.ProfileNames())
+	alt := f.Profiles["alt"]
+	require.Equal(t, "alt-agent", alt.UserAgent)
+	require.Len(t, alt.Rules, 1)
+
+	// Unset credentials fall back to the given value.
+	clientID, err := f.Profiles["personal"].Credentials.ClientID.ResolveOr("shared")
+	require.NoError(t, err)
+	require.Equal(t, "shared", clientID)
+	clientID, err = alt.Credentials.ClientID.ResolveOr("shared")
+	require.NoError(t, err)
+	require.Equal(t, "other", clientID)
+}
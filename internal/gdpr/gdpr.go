@@ -0,0 +1,224 @@
+// Package gdpr parses the data export that Reddit provides on request (see
+// https://www.reddit.com/settings/data-request). The export is a zip file of
+// CSV files; this package expects it to have already been unzipped.
+package gdpr
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ccampo133/shreddit-go/internal/reddit"
+)
+
+const (
+	commentsFile      = "comments.csv"
+	postsFile         = "posts.csv"
+	savedCommentsFile = "saved_comments.csv"
+	savedPostsFile    = "saved_posts.csv"
+
+	// dateLayout is the layout of the timestamps in the export, e.g.
+	// "2024-10-30 18:04:05 UTC".
+	dateLayout = "2006-01-02 15:04:05 MST"
+)
+
+// Export is the parsed contents of an unzipped GDPR data export.
+//
+// The export doesn't contain everything that Reddit's listing APIs return. In
+// particular, there are no scores, and saved things have no creation time or
+// body, so those fields are left as their zero values.
+type Export struct {
+	Comments      []reddit.Comment
+	Posts         []reddit.Post
+	SavedComments []reddit.Comment
+	SavedPosts    []reddit.Post
+}
+
+// Load parses the export in the given directory. Missing files are treated as
+// empty, since Reddit omits files for which there is no data.
+func Load(dir string) (*Export, error) {
+	var export Export
+	err := readCSV(
+		filepath.Join(dir, commentsFile), func(row record) error {
+			comment, err := row.comment()
+			if err != nil {
+				return err
+			}
+			comment.Body = row.get("body")
+			export.Comments = append(export.Comments, comment)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	err = readCSV(
+		filepath.Join(dir, postsFile), func(row record) error {
+			post, err := row.post()
+			if err != nil {
+				return err
+			}
+			post.Title = row.get("title")
+			export.Posts = append(export.Posts, post)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	err = readCSV(
+		filepath.Join(dir, savedCommentsFile), func(row record) error {
+			comment, err := row.comment()
+			if err != nil {
+				return err
+			}
+			export.SavedComments = append(export.SavedComments, comment)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	err = readCSV(
+		filepath.Join(dir, savedPostsFile), func(row record) error {
+			post, err := row.post()
+			if err != nil {
+				return err
+			}
+			export.SavedPosts = append(export.SavedPosts, post)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// record is a single CSV row, keyed by the header row's column names.
+type record map[string]string
+
+func (r record) get(col string) string {
+	return r[col]
+}
+
+// comment returns a comment populated with the fields common to all comment
+// files in the export.
+func (r record) comment() (reddit.Comment, error) {
+	created, err := r.created()
+	if err != nil {
+		return reddit.Comment{}, err
+	}
+	permalink := normalizePermalink(r.get("permalink"))
+	return reddit.Comment{
+		ID:         r.get("id"),
+		Permalink:  permalink,
+		Subreddit:  r.subreddit(permalink),
+		CreatedUTC: created,
+	}, nil
+}
+
+// post returns a post populated with the fields common to all post files in
+// the export.
+func (r record) post() (reddit.Post, error) {
+	created, err := r.created()
+	if err != nil {
+		return reddit.Post{}, err
+	}
+	permalink := normalizePermalink(r.get("permalink"))
+	return reddit.Post{
+		ID:         r.get("id"),
+		Permalink:  permalink,
+		Subreddit:  r.subreddit(permalink),
+		CreatedUTC: created,
+	}, nil
+}
+
+// created parses the "date" column, if there is one.
+func (r record) created() (reddit.Time, error) {
+	date := r.get("date")
+	if date == "" {
+		return reddit.Time{}, nil
+	}
+	t, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return reddit.Time{}, fmt.Errorf("error parsing date %q: %w", date, err)
+	}
+	return reddit.Time{Time: t}, nil
+}
+
+// subreddit returns the "subreddit" column, falling back to parsing it out of
+// the permalink for files which don't have that column.
+func (r record) subreddit(permalink string) string {
+	if sub := r.get("subreddit"); sub != "" {
+		return sub
+	}
+	// Permalinks look like /r/{subreddit}/comments/...
+	parts := strings.Split(strings.Trim(permalink, "/"), "/")
+	if len(parts) > 1 && parts[0] == "r" {
+		return parts[1]
+	}
+	return ""
+}
+
+// normalizePermalink strips the scheme and host from the export's permalinks,
+// which are full URLs, so that they match the paths returned by the API.
+func normalizePermalink(permalink string) string {
+	u, err := url.Parse(permalink)
+	if err != nil || u.Host == "" {
+		return permalink
+	}
+	return u.Path
+}
+
+// readCSV reads the CSV file at the given path and calls fn with each row. If
+// the file doesn't exist, fn is never called and no error is returned.
+func readCSV(path string, fn func(record) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("error opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	header, err := r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("error reading header of %s: %w", path, err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", path, err)
+		}
+		rec := make(record, len(header))
+		for i, col := range header {
+			if i < len(row) {
+				rec[col] = row[i]
+			}
+		}
+		if err := fn(rec); err != nil {
+			return fmt.Errorf("error parsing %s: %w", path, err)
+		}
+	}
+}
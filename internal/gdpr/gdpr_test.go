@@ -0,0 +1,63 @@
+package gdpr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(
+		t, dir, commentsFile, "\ufeffid,permalink,date,ip,subreddit,gildings,link,parent,body,media\n"+
+			"abc,https://www.reddit.com/r/golang/comments/xyz/title/abc/,2024-10-30 18:04:05 UTC,,golang,0,"+
+			"https://www.reddit.com/r/golang/comments/xyz/title/,t3_xyz,\"Hello, world\",\n",
+	)
+	writeFile(
+		t, dir, postsFile, "id,permalink,date,ip,subreddit,gildings,title,url,body\n"+
+			"xyz,https://www.reddit.com/r/golang/comments/xyz/title/,2024-10-29 01:02:03 UTC,,golang,0,A title,,Some text\n",
+	)
+	writeFile(
+		t, dir, savedCommentsFile, "id,permalink\n"+
+			"def,https://www.reddit.com/r/rust/comments/uvw/title/def/\n",
+	)
+	// No saved_posts.csv - should be treated as empty.
+
+	export, err := Load(dir)
+	require.NoError(t, err)
+
+	require.Len(t, export.Comments, 1)
+	comment := export.Comments[0]
+	require.Equal(t, "abc", comment.ID)
+	require.Equal(t, "Hello, world", comment.Body)
+	require.Equal(t, "golang", comment.Subreddit)
+	require.Equal(t, "/r/golang/comments/xyz/title/abc/", comment.Permalink)
+	require.True(t, comment.CreatedUTC.Equal(time.Date(2024, 10, 30, 18, 4, 5, 0, time.UTC)))
+
+	require.Len(t, export.Posts, 1)
+	require.Equal(t, "xyz", export.Posts[0].ID)
+	require.Equal(t, "A title", export.Posts[0].Title)
+
+	require.Len(t, export.SavedComments, 1)
+	require.Equal(t, "def", export.SavedComments[0].ID)
+	require.Equal(t, "rust", export.SavedComments[0].Subreddit)
+	require.True(t, export.SavedComments[0].CreatedUTC.IsZero())
+
+	require.Empty(t, export.SavedPosts)
+}
+
+func TestLoad_InvalidDate(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, postsFile, "id,permalink,date\nxyz,/r/golang/comments/xyz/,yesterday\n")
+
+	_, err := Load(dir)
+	require.ErrorContains(t, err, `error parsing date "yesterday"`)
+}
+
+func writeFile(t *testing.T, dir, name, contents string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600))
+}
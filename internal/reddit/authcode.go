@@ -0,0 +1,206 @@
+package reddit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// DefaultRedirectURL is the redirect URL that the README tells users to
+	// register for their app.
+	DefaultRedirectURL = "http://localhost:8080"
+	// authURL is where users authorize apps in the authorization code flow.
+	authURL = "https://www.reddit.com/api/v1/authorize"
+)
+
+// scopes are the OAuth2 scopes that shreddit needs to find and remove
+// everything. See https://www.reddit.com/api/v1/scopes.
+var scopes = []string{
+	"identity",
+	"history",
+	"read",
+	"edit",
+	"save",
+	"vote",
+	"subscribe",
+	"mysubreddits",
+	"privatemessages",
+	"report",
+}
+
+// Authorize gets a token with the authorization code flow, for apps registered
+// as "installed" (or "script") apps with the given redirect URL, which must be
+// a loopback http:// URL. It serves the redirect URL locally, calls prompt
+// with the URL that the user must visit to authorize the app, and waits for
+// Reddit to redirect them back. The token is permanent, i.e. it includes a
+// refresh token, so it can be saved with SaveToken and reused indefinitely.
+func Authorize(ctx context.Context, cfg Config, redirectURL string, prompt func(authURL string)) (*oauth2.Token, error) {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = defaultUserAgent
+	}
+	redirect, err := url.Parse(redirectURL)
+	if err != nil || redirect.Scheme != "http" || redirect.Host == "" {
+		return nil, fmt.Errorf("invalid redirect URL %q: must be an http:// URL", redirectURL)
+	}
+	ln, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return nil, fmt.Errorf("error listening for authorization redirect: %w", err)
+	}
+	if redirect.Port() == "0" {
+		// Mostly useful for tests, since Reddit requires an exact match.
+		redirect.Host = ln.Addr().String()
+	}
+	state, err := randomState()
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+	var once sync.Once
+	path := redirect.Path
+	if path == "" {
+		path = "/"
+	}
+	server := &http.Server{
+		Handler: http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != path {
+					http.NotFound(w, r)
+					return
+				}
+				query := r.URL.Query()
+				// Ignore requests which didn't come from this authorization
+				// attempt.
+				if query.Get("state") != state {
+					http.Error(w, "Invalid state; please try authorizing again.", http.StatusBadRequest)
+					return
+				}
+				res := result{code: query.Get("code")}
+				if e := query.Get("error"); e != "" {
+					res.err = fmt.Errorf("authorization failed: %s", e)
+					http.Error(w, "Authorization failed; you can close this window.", http.StatusForbidden)
+				} else {
+					_, _ = fmt.Fprintln(w, "Authorized! You can close this window and return to shreddit.")
+				}
+				once.Do(func() { results <- res })
+			},
+		),
+	}
+	go func() { _ = server.Serve(ln) }()
+	defer server.Close()
+
+	oauthCfg := newOAuthConfig(cfg, redirect.String())
+	oauthCfg.Scopes = scopes
+	prompt(oauthCfg.AuthCodeURL(state, oauth2.SetAuthURLParam("duration", "permanent")))
+	var res result
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res = <-results:
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+	tok, err := oauthCfg.Exchange(oauthContext(ctx, cfg), res.code)
+	if err != nil {
+		return nil, fmt.Errorf("error exchanging authorization code: %w", err)
+	}
+	return tok, nil
+}
+
+func randomState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating state: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// LoadToken reads a token saved by SaveToken.
+func LoadToken(path string) (*oauth2.Token, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading token file: %w", err)
+	}
+	var tok oauth2.Token
+	if err := json.Unmarshal(b, &tok); err != nil {
+		return nil, fmt.Errorf("error unmarshalling token file: %w", err)
+	}
+	if tok.RefreshToken == "" {
+		return nil, errors.New("token file has no refresh token")
+	}
+	return &tok, nil
+}
+
+// SaveToken saves a token to the given path, which is only readable by the
+// current user. The file is written to a temporary file first and renamed, so
+// that it is never left partially written.
+func SaveToken(path string, tok *oauth2.Token) error {
+	b, err := json.Marshal(tok)
+	if err != nil {
+		return fmt.Errorf("error marshalling token: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating token file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("error writing token file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing token file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error saving token file: %w", err)
+	}
+	return nil
+}
+
+// fileTokenSource gets new access tokens with a refresh token, and saves them
+// to a token file, so that the next run doesn't have to get one again. Unlike
+// oauth2.Config.TokenSource, it always gets a new token, since it's only
+// called when the current one can't be used.
+type fileTokenSource struct {
+	ctx          context.Context
+	oauthCfg     *oauth2.Config
+	path         string
+	mu           sync.Mutex
+	refreshToken string
+}
+
+func (s *fileTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// The token has no access token, so it's refreshed straight away. If
+	// Reddit doesn't return a new refresh token, the old one is kept.
+	tok, err := s.oauthCfg.TokenSource(s.ctx, &oauth2.Token{RefreshToken: s.refreshToken}).Token()
+	if err != nil {
+		return nil, err
+	}
+	s.refreshToken = tok.RefreshToken
+	if err := SaveToken(s.path, tok); err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
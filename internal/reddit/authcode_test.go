@@ -0,0 +1,123 @@
+package reddit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestAuthorize(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/api/v1/access_token", r.URL.Path)
+				require.NoError(t, r.ParseForm())
+				require.Equal(t, "authorization_code", r.PostForm.Get("grant_type"))
+				require.Equal(t, "test_code", r.PostForm.Get("code"))
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write(
+					[]byte(`{"access_token": "test_token", "refresh_token": "test_refresh", "token_type": "bearer", "expires_in": 3600}`),
+				)
+			},
+		),
+	)
+	defer server.Close()
+
+	cfg := Config{BaseURL: server.URL, ClientID: "test_client_id"}
+	tok, err := Authorize(
+		context.Background(), cfg, "http://127.0.0.1:0/callback", func(authURL string) {
+			// Play the part of the browser: check the authorization URL and
+			// follow Reddit's redirect back to the callback server.
+			u, err := url.Parse(authURL)
+			require.NoError(t, err)
+			query := u.Query()
+			require.Equal(t, "test_client_id", query.Get("client_id"))
+			require.Equal(t, "code", query.Get("response_type"))
+			require.Equal(t, "permanent", query.Get("duration"))
+			require.Contains(t, query.Get("scope"), "history")
+
+			// Requests with the wrong state are rejected.
+			resp, err := http.Get(query.Get("redirect_uri") + "?code=bad&state=wrong")
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+			resp, err = http.Get(query.Get("redirect_uri") + "?code=test_code&state=" + query.Get("state"))
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "test_token", tok.AccessToken)
+	require.Equal(t, "test_refresh", tok.RefreshToken)
+}
+
+func TestAuthorize_Denied(t *testing.T) {
+	_, err := Authorize(
+		context.Background(), Config{}, "http://127.0.0.1:0", func(authURL string) {
+			u, err := url.Parse(authURL)
+			require.NoError(t, err)
+			query := u.Query()
+			resp, err := http.Get(query.Get("redirect_uri") + "?error=access_denied&state=" + query.Get("state"))
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+		},
+	)
+	require.ErrorContains(t, err, "authorization failed: access_denied")
+}
+
+func TestAuthorize_InvalidRedirectURL(t *testing.T) {
+	_, err := Authorize(context.Background(), Config{}, "https://example.com", func(string) {})
+	require.ErrorContains(t, err, "must be an http:// URL")
+}
+
+func TestNewOAuth2Client_TokenFile(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, r.ParseForm())
+				require.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+				require.Equal(t, "test_refresh", r.PostForm.Get("refresh_token"))
+				w.Header().Set("Content-Type", "application/json")
+				// Reddit doesn't send the refresh token again.
+				_, _ = w.Write([]byte(`{"access_token": "new_token", "token_type": "bearer", "expires_in": 3600}`))
+			},
+		),
+	)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "token.json")
+	expired := &oauth2.Token{
+		AccessToken:  "old_token",
+		RefreshToken: "test_refresh",
+		TokenType:    "bearer",
+		Expiry:       time.Now().Add(-time.Hour),
+	}
+	require.NoError(t, SaveToken(path, expired))
+
+	cfg := Config{BaseURL: server.URL, ClientID: "test_client_id", TokenFile: path}
+	client, err := NewOAuth2Client(context.Background(), cfg)
+	require.NoError(t, err)
+	require.NotNil(t, client)
+
+	// The refreshed token is saved, keeping the refresh token.
+	tok, err := LoadToken(path)
+	require.NoError(t, err)
+	require.Equal(t, "new_token", tok.AccessToken)
+	require.Equal(t, "test_refresh", tok.RefreshToken)
+}
+
+func TestLoadToken_NoRefreshToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.json")
+	require.NoError(t, SaveToken(path, &oauth2.Token{AccessToken: "test_token"}))
+	_, err := LoadToken(path)
+	require.ErrorContains(t, err, "token file has no refresh token")
+}
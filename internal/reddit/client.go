@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/go-resty/resty/v2"
 )
@@ -17,6 +21,52 @@ var (
 	ErrRateLimited = fmt.Errorf("rate limited")
 )
 
+// Sort is the order in which the things in a listing are sorted.
+type Sort string
+
+const (
+	SortNew           Sort = "new"
+	SortTop           Sort = "top"
+	SortControversial Sort = "controversial"
+	SortHot           Sort = "hot"
+)
+
+// TimeWindow restricts a listing to things created within a period of time.
+// It only applies to the "top" and "controversial" sorts.
+type TimeWindow string
+
+const (
+	TimeWindowHour  TimeWindow = "hour"
+	TimeWindowDay   TimeWindow = "day"
+	TimeWindowWeek  TimeWindow = "week"
+	TimeWindowMonth TimeWindow = "month"
+	TimeWindowYear  TimeWindow = "year"
+	TimeWindowAll   TimeWindow = "all"
+)
+
+// ListingOptions are the options for requesting a page of a user's comments
+// or posts. The zero value requests the first page sorted by "new".
+type ListingOptions struct {
+	// After is the fullname of the last thing of the previous page.
+	After string
+	Sort  Sort
+	Time  TimeWindow
+}
+
+func (o ListingOptions) params() map[string]string {
+	params := make(map[string]string)
+	if o.After != "" {
+		params["after"] = o.After
+	}
+	if o.Sort != "" {
+		params["sort"] = string(o.Sort)
+	}
+	if o.Time != "" {
+		params["t"] = string(o.Time)
+	}
+	return params
+}
+
 // TODO: doc -2024-10-22
 type Config struct {
 	BaseURL      string
@@ -25,11 +75,19 @@ type Config struct {
 	Username     string
 	Password     string
 	UserAgent    string
+	// TOTPSecret is the base32 secret of the account's two-factor
+	// authentication, if it is enabled.
+	TOTPSecret string
+	// TokenFile, if set, is the path of a token saved by Authorize, which is
+	// used instead of the password grant. Username, Password and TOTPSecret
+	// aren't needed in that case.
+	TokenFile string
 }
 
 // TODO: doc -2024-10-22
 type Client struct {
-	rc *resty.Client
+	rc      *resty.Client
+	limiter *rateLimiter
 }
 
 // TODO: doc -2024-10-22
@@ -47,22 +105,50 @@ func NewClient(ctx context.Context, cfg Config) (*Client, error) {
 		return nil, err
 	}
 
+	limiter := newRateLimiter()
 	rc := resty.NewWithClient(httpClient).
 		SetBaseURL(cfg.BaseURL).
-		SetHeader("User-Agent", cfg.UserAgent)
-	return &Client{rc: rc}, nil
+		SetHeader("User-Agent", cfg.UserAgent).
+		OnBeforeRequest(
+			func(_ *resty.Client, req *resty.Request) error {
+				return limiter.wait(req.Context())
+			},
+		).
+		OnAfterResponse(
+			func(_ *resty.Client, resp *resty.Response) error {
+				limiter.update(resp.Header())
+				if resp.StatusCode() == http.StatusTooManyRequests {
+					wait := retryAfter(resp.Header())
+					slog.Warn("Rate limited by Reddit; waiting before retrying", "wait", wait)
+					limiter.block(wait)
+				}
+				return nil
+			},
+		).
+		// Retry requests which were rate limited. The limiter will have been
+		// blocked by the response hook, so the retry waits as long as Reddit
+		// asked us to.
+		SetRetryCount(maxRateLimitRetries).
+		AddRetryCondition(
+			func(resp *resty.Response, _ error) bool {
+				return resp != nil && resp.StatusCode() == http.StatusTooManyRequests
+			},
+		)
+	return &Client{rc: rc, limiter: limiter}, nil
 }
 
 // TODO: doc -2024-10-30
-func (c *Client) GetPosts(username, after string) (*Listing[Post], error) {
-	req := c.rc.R()
-	if after != "" {
-		req.SetQueryParam("after", after)
-	}
+func (c *Client) GetPosts(ctx context.Context, username string, opts ListingOptions) (*Listing[Post], error) {
+	req := c.rc.R().
+		SetContext(ctx).
+		SetQueryParams(opts.params())
 	resp, err := req.Get(fmt.Sprintf("/user/%s/submitted.json", username))
 	if err != nil {
 		return nil, fmt.Errorf("error getting posts: %w", err)
 	}
+	if err := checkResponse(resp); err != nil {
+		return nil, fmt.Errorf("error getting posts: %w", err)
+	}
 	var body Listing[Post]
 	if err := json.Unmarshal(resp.Body(), &body); err != nil {
 		return nil, fmt.Errorf("error unmarshalling post listing: %w", err)
@@ -71,8 +157,9 @@ func (c *Client) GetPosts(username, after string) (*Listing[Post], error) {
 }
 
 // TODO: doc -2024-10-30
-func (c *Client) GetSavedPosts(username, after string) (*Listing[Comment], error) {
+func (c *Client) GetSavedPosts(ctx context.Context, username, after string) (*Listing[Post], error) {
 	req := c.rc.R().
+		SetContext(ctx).
 		SetQueryParams(map[string]string{"type": "links"})
 	if after != "" {
 		req.SetQueryParam("after", after)
@@ -81,23 +168,146 @@ func (c *Client) GetSavedPosts(username, after string) (*Listing[Comment], error
 	if err != nil {
 		return nil, fmt.Errorf("error getting saved posts: %w", err)
 	}
-	var body Listing[Comment]
+	if err := checkResponse(resp); err != nil {
+		return nil, fmt.Errorf("error getting saved posts: %w", err)
+	}
+	var body Listing[Post]
 	if err := json.Unmarshal(resp.Body(), &body); err != nil {
 		return nil, fmt.Errorf("error unmarshalling post listing: %w", err)
 	}
 	return &body, nil
 }
 
-// TODO: doc -2024-10-22
-func (c *Client) GetComments(username, after string) (*Listing[Comment], error) {
-	req := c.rc.R()
+// GetUpvotedPosts returns a page of the posts that the user has upvoted. Reddit
+// only lists votes on posts, not on comments.
+func (c *Client) GetUpvotedPosts(ctx context.Context, username, after string) (*Listing[Post], error) {
+	listing, err := c.getUserPosts(ctx, username, "upvoted", after)
+	if err != nil {
+		return nil, fmt.Errorf("error getting upvoted posts: %w", err)
+	}
+	return listing, nil
+}
+
+// GetDownvotedPosts is like GetUpvotedPosts, but for downvotes.
+func (c *Client) GetDownvotedPosts(ctx context.Context, username, after string) (*Listing[Post], error) {
+	listing, err := c.getUserPosts(ctx, username, "downvoted", after)
+	if err != nil {
+		return nil, fmt.Errorf("error getting downvoted posts: %w", err)
+	}
+	return listing, nil
+}
+
+// getUserPosts returns a page of one of the user's listings of posts, e.g.
+// "upvoted".
+func (c *Client) getUserPosts(ctx context.Context, username, where, after string) (*Listing[Post], error) {
+	req := c.rc.R().SetContext(ctx)
+	if after != "" {
+		req.SetQueryParam("after", after)
+	}
+	resp, err := req.Get(fmt.Sprintf("/user/%s/%s.json", username, where))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+	var body Listing[Post]
+	if err := json.Unmarshal(resp.Body(), &body); err != nil {
+		return nil, fmt.Errorf("error unmarshalling post listing: %w", err)
+	}
+	return &body, nil
+}
+
+// GetHiddenPosts returns a page of the posts that the user has hidden.
+func (c *Client) GetHiddenPosts(ctx context.Context, username, after string) (*Listing[Post], error) {
+	listing, err := c.getUserPosts(ctx, username, "hidden", after)
+	if err != nil {
+		return nil, fmt.Errorf("error getting hidden posts: %w", err)
+	}
+	return listing, nil
+}
+
+// GetInboxMessages returns a page of the private messages that the user has
+// received. The inbox also lists replies to the user's comments and posts,
+// which aren't messages, so they are left out; the cursor of the next page
+// still accounts for them.
+func (c *Client) GetInboxMessages(ctx context.Context, after string) (*Listing[Message], error) {
+	listing, err := c.getMessages(ctx, "inbox", after)
+	if err != nil {
+		return nil, fmt.Errorf("error getting inbox messages: %w", err)
+	}
+	return listing, nil
+}
+
+// GetSentMessages returns a page of the private messages that the user has
+// sent.
+func (c *Client) GetSentMessages(ctx context.Context, after string) (*Listing[Message], error) {
+	listing, err := c.getMessages(ctx, "sent", after)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sent messages: %w", err)
+	}
+	return listing, nil
+}
+
+func (c *Client) getMessages(ctx context.Context, where, after string) (*Listing[Message], error) {
+	req := c.rc.R().SetContext(ctx)
+	if after != "" {
+		req.SetQueryParam("after", after)
+	}
+	resp, err := req.Get(fmt.Sprintf("/message/%s.json", where))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+	var body Listing[Message]
+	if err := json.Unmarshal(resp.Body(), &body); err != nil {
+		return nil, fmt.Errorf("error unmarshalling message listing: %w", err)
+	}
+	body.Data.Children = slices.DeleteFunc(
+		body.Data.Children, func(child Thing[Message]) bool {
+			return child.Kind != KindMessage
+		},
+	)
+	return &body, nil
+}
+
+// GetSubscriptions returns a page of the subreddits that the user is
+// subscribed to.
+func (c *Client) GetSubscriptions(ctx context.Context, after string) (*Listing[Subreddit], error) {
+	req := c.rc.R().
+		SetContext(ctx).
+		SetQueryParam("limit", "100")
 	if after != "" {
 		req.SetQueryParam("after", after)
 	}
+	resp, err := req.Get("/subreddits/mine/subscriber.json")
+	if err != nil {
+		return nil, fmt.Errorf("error getting subscriptions: %w", err)
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, fmt.Errorf("error getting subscriptions: %w", err)
+	}
+	var body Listing[Subreddit]
+	if err := json.Unmarshal(resp.Body(), &body); err != nil {
+		return nil, fmt.Errorf("error unmarshalling subreddit listing: %w", err)
+	}
+	return &body, nil
+}
+
+// TODO: doc -2024-10-22
+func (c *Client) GetComments(ctx context.Context, username string, opts ListingOptions) (*Listing[Comment], error) {
+	req := c.rc.R().
+		SetContext(ctx).
+		SetQueryParams(opts.params())
 	resp, err := req.Get(fmt.Sprintf("/user/%s/comments.json", username))
 	if err != nil {
 		return nil, fmt.Errorf("error getting comments: %w", err)
 	}
+	if err := checkResponse(resp); err != nil {
+		return nil, fmt.Errorf("error getting comments: %w", err)
+	}
 	var body Listing[Comment]
 	if err := json.Unmarshal(resp.Body(), &body); err != nil {
 		return nil, fmt.Errorf("error unmarshalling comment listing: %w", err)
@@ -106,8 +316,9 @@ func (c *Client) GetComments(username, after string) (*Listing[Comment], error)
 }
 
 // TODO: doc -2024-10-30
-func (c *Client) GetSavedComments(username, after string) (*Listing[Comment], error) {
+func (c *Client) GetSavedComments(ctx context.Context, username, after string) (*Listing[Comment], error) {
 	req := c.rc.R().
+		SetContext(ctx).
 		SetQueryParams(map[string]string{"type": "comments"})
 	if after != "" {
 		req.SetQueryParam("after", after)
@@ -116,6 +327,9 @@ func (c *Client) GetSavedComments(username, after string) (*Listing[Comment], er
 	if err != nil {
 		return nil, fmt.Errorf("error getting saved comments: %w", err)
 	}
+	if err := checkResponse(resp); err != nil {
+		return nil, fmt.Errorf("error getting saved comments: %w", err)
+	}
 	var body Listing[Comment]
 	if err := json.Unmarshal(resp.Body(), &body); err != nil {
 		return nil, fmt.Errorf("error unmarshalling comment listing: %w", err)
@@ -123,78 +337,305 @@ func (c *Client) GetSavedComments(username, after string) (*Listing[Comment], er
 	return &body, nil
 }
 
-// TODO: doc -2024-10-25
-func (c *Client) EditComment(id, body string) error {
-	fullName := commentFullName(id)
+// GetFriends returns all friends of the authenticated user.
+func (c *Client) GetFriends(ctx context.Context) ([]User, error) {
 	resp, err := c.rc.R().
-		SetQueryParams(map[string]string{"raw_json": "1"}).
-		SetFormData(map[string]string{"thing_id": fullName, "text": body}).
-		Post("/api/editusertext")
+		SetContext(ctx).
+		Get("/api/v1/me/friends")
 	if err != nil {
-		return fmt.Errorf("error editing comment with id %s: %w", fullName, err)
+		return nil, fmt.Errorf("error getting friends: %w", err)
 	}
-	var editResp EditResponse
-	if err := json.Unmarshal(resp.Body(), &editResp); err != nil {
-		return fmt.Errorf("error unmarshalling edit response: %w", err)
+	if err := checkResponse(resp); err != nil {
+		return nil, fmt.Errorf("error getting friends: %w", err)
 	}
-	if !editResp.Success {
-		if editResp.IsRateLimited() {
-			return ErrRateLimited
+	var body UserList
+	if err := json.Unmarshal(resp.Body(), &body); err != nil {
+		return nil, fmt.Errorf("error unmarshalling friend list: %w", err)
+	}
+	return body.Data.Children, nil
+}
+
+// Unfriend removes the user with the given username from the authenticated
+// user's friends.
+func (c *Client) Unfriend(ctx context.Context, username string) error {
+	resp, err := c.rc.R().
+		SetContext(ctx).
+		SetPathParam("username", username).
+		Delete("/api/v1/me/friends/{username}")
+	if err != nil {
+		return fmt.Errorf("error unfriending user %s: %w", username, err)
+	}
+	if err := checkResponse(resp); err != nil {
+		return fmt.Errorf("error unfriending user %s: %w", username, err)
+	}
+	return nil
+}
+
+// maxInfoFullnames is the most things that /api/info returns at once.
+const maxInfoFullnames = 100
+
+// Things is a set of comments and posts, as returned by GetThingsByFullname.
+type Things struct {
+	Comments []Comment
+	Posts    []Post
+}
+
+// GetThingsByFullname returns the comments and posts with the given fullnames
+// as they currently are, e.g. to check that they were edited. Things which no
+// longer exist are omitted. Any number of fullnames can be given; they are
+// requested in batches. Only comments and posts can be looked up, so an error
+// is returned for fullnames of any other kind.
+func (c *Client) GetThingsByFullname(ctx context.Context, fullnames []Fullname) (*Things, error) {
+	ids := make([]string, 0, len(fullnames))
+	for _, fullname := range fullnames {
+		if (fullname.Kind != KindComment && fullname.Kind != KindPost) || fullname.ID == "" {
+			return nil, fmt.Errorf("invalid fullname %q: only comments and posts can be looked up", fullname)
+		}
+		ids = append(ids, fullname.String())
+	}
+	var things Things
+	for len(ids) > 0 {
+		batch := ids[:min(len(ids), maxInfoFullnames)]
+		ids = ids[len(batch):]
+		if err := c.getInfo(ctx, batch, &things); err != nil {
+			return nil, fmt.Errorf("error getting things by fullname: %w", err)
+		}
+	}
+	return &things, nil
+}
+
+// getInfo gets a single batch of things from /api/info and adds them to
+// things.
+func (c *Client) getInfo(ctx context.Context, fullnames []string, things *Things) error {
+	resp, err := c.rc.R().
+		SetContext(ctx).
+		SetQueryParam("id", strings.Join(fullnames, ",")).
+		Get("/api/info")
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+	var body Listing[json.RawMessage]
+	if err := json.Unmarshal(resp.Body(), &body); err != nil {
+		return fmt.Errorf("error unmarshalling info: %w", err)
+	}
+	for _, child := range body.Data.Children {
+		switch child.Kind {
+		case KindComment:
+			var comment Comment
+			if err := json.Unmarshal(child.Data, &comment); err != nil {
+				return fmt.Errorf("error unmarshalling comment: %w", err)
+			}
+			things.Comments = append(things.Comments, comment)
+		case KindPost:
+			var post Post
+			if err := json.Unmarshal(child.Data, &post); err != nil {
+				return fmt.Errorf("error unmarshalling post: %w", err)
+			}
+			things.Posts = append(things.Posts, post)
 		}
-		// TODO: log the response -2024-10-25
-		return fmt.Errorf("API failure editing comment with id %s", fullName)
 	}
 	return nil
 }
 
+// GetMe returns the authenticated user's account, e.g. to find out their
+// username when authenticating with a token rather than a password.
+func (c *Client) GetMe(ctx context.Context) (*Account, error) {
+	resp, err := c.rc.R().
+		SetContext(ctx).
+		Get("/api/v1/me")
+	if err != nil {
+		return nil, fmt.Errorf("error getting account: %w", err)
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, fmt.Errorf("error getting account: %w", err)
+	}
+	var account Account
+	if err := json.Unmarshal(resp.Body(), &account); err != nil {
+		return nil, fmt.Errorf("error unmarshalling account: %w", err)
+	}
+	return &account, nil
+}
+
+// TODO: doc -2024-10-25
+func (c *Client) EditComment(ctx context.Context, id, body string) error {
+	fullName := commentFullName(id)
+	if err := c.editThing(ctx, fullName, body); err != nil {
+		return fmt.Errorf("error editing comment with id %s: %w", fullName, err)
+	}
+	return nil
+}
+
+// EditPost replaces the text of a self post. Link posts can't be edited.
+func (c *Client) EditPost(ctx context.Context, id, body string) error {
+	fullName := postFullName(id)
+	if err := c.editThing(ctx, fullName, body); err != nil {
+		return fmt.Errorf("error editing post with id %s: %w", fullName, err)
+	}
+	return nil
+}
+
+// editThing replaces the text of a comment or self post.
+func (c *Client) editThing(ctx context.Context, fullName, body string) error {
+	// Reddit reports some rate limiting in the body of a successful response
+	// rather than with a 429, so retry those here.
+	for attempt := 1; ; attempt++ {
+		resp, err := c.rc.R().
+			SetContext(ctx).
+			SetQueryParams(map[string]string{"raw_json": "1"}).
+			SetFormData(map[string]string{"thing_id": fullName, "text": body}).
+			Post("/api/editusertext")
+		if err != nil {
+			return err
+		}
+		if err := checkResponse(resp); err != nil {
+			return err
+		}
+		var editResp EditResponse
+		if err := json.Unmarshal(resp.Body(), &editResp); err != nil {
+			return fmt.Errorf("error unmarshalling edit response: %w", err)
+		}
+		if editResp.Success {
+			return nil
+		}
+		apiErr := editResp.editError(resp.StatusCode())
+		if !editResp.IsRateLimited() || attempt > maxRateLimitRetries {
+			slog.Warn("Failed to edit thing", "id", fullName, "response", string(resp.Body()))
+			return apiErr
+		}
+		wait := parseRateLimitWait(string(resp.Body()))
+		slog.Warn("Rate limited editing thing; waiting before retrying", "id", fullName, "wait", wait)
+		c.limiter.block(wait)
+	}
+}
+
 // TODO: doc -2024-10-30
-func (c *Client) UnsaveComment(id string) error {
+func (c *Client) UnsaveComment(ctx context.Context, id string) error {
 	fullName := commentFullName(id)
-	if err := c.unsaveThing(fullName); err != nil {
+	if err := c.unsaveThing(ctx, fullName); err != nil {
 		return fmt.Errorf("error unsaving comment with id %s: %w", fullName, err)
 	}
 	return nil
 }
 
 // TODO: doc -2024-10-30
-func (c *Client) UnsavePost(id string) error {
+func (c *Client) UnsavePost(ctx context.Context, id string) error {
 	fullName := postFullName(id)
-	if err := c.unsaveThing(fullName); err != nil {
+	if err := c.unsaveThing(ctx, fullName); err != nil {
 		return fmt.Errorf("error unsaving post with id %s: %w", fullName, err)
 	}
 	return nil
 }
 
+// UnvotePost removes the user's vote, up or down, from a post.
+func (c *Client) UnvotePost(ctx context.Context, id string) error {
+	fullName := postFullName(id)
+	resp, err := c.rc.R().
+		SetContext(ctx).
+		SetFormData(map[string]string{"id": fullName, "dir": "0"}).
+		Post("/api/vote")
+	if err != nil {
+		return fmt.Errorf("error unvoting post with id %s: %w", fullName, err)
+	}
+	if err := checkResponse(resp); err != nil {
+		return fmt.Errorf("error unvoting post with id %s: %w", fullName, err)
+	}
+	return nil
+}
+
+// UnhidePost unhides a post that the user has hidden.
+func (c *Client) UnhidePost(ctx context.Context, id string) error {
+	fullName := postFullName(id)
+	resp, err := c.rc.R().
+		SetContext(ctx).
+		SetFormData(map[string]string{"id": fullName}).
+		Post("/api/unhide")
+	if err != nil {
+		return fmt.Errorf("error unhiding post with id %s: %w", fullName, err)
+	}
+	if err := checkResponse(resp); err != nil {
+		return fmt.Errorf("error unhiding post with id %s: %w", fullName, err)
+	}
+	return nil
+}
+
+// DeleteMessage deletes a private message from the user's inbox or sent
+// messages. The other party keeps their copy.
+func (c *Client) DeleteMessage(ctx context.Context, id string) error {
+	fullName := messageFullName(id)
+	resp, err := c.rc.R().
+		SetContext(ctx).
+		SetFormData(map[string]string{"id": fullName}).
+		Post("/api/del_msg")
+	if err != nil {
+		return fmt.Errorf("error deleting message with id %s: %w", fullName, err)
+	}
+	if err := checkResponse(resp); err != nil {
+		return fmt.Errorf("error deleting message with id %s: %w", fullName, err)
+	}
+	return nil
+}
+
+// Unsubscribe unsubscribes the user from the subreddits with the given IDs,
+// all in a single request.
+func (c *Client) Unsubscribe(ctx context.Context, ids []string) error {
+	fullnames := make([]string, 0, len(ids))
+	for _, id := range ids {
+		fullnames = append(fullnames, subredditFullName(id))
+	}
+	resp, err := c.rc.R().
+		SetContext(ctx).
+		SetFormData(map[string]string{"action": "unsub", "sr": strings.Join(fullnames, ",")}).
+		Post("/api/subscribe")
+	if err != nil {
+		return fmt.Errorf("error unsubscribing from subreddits: %w", err)
+	}
+	if err := checkResponse(resp); err != nil {
+		return fmt.Errorf("error unsubscribing from subreddits: %w", err)
+	}
+	return nil
+}
+
 // TODO: doc -2024-10-25
-func (c *Client) DeleteComment(id string) error {
+func (c *Client) DeleteComment(ctx context.Context, id string) error {
 	fullName := commentFullName(id)
-	if err := c.deleteThing(fullName); err != nil {
+	if err := c.deleteThing(ctx, fullName); err != nil {
 		return fmt.Errorf("error deleting comment with id %s: %w", fullName, err)
 	}
 	return nil
 }
 
 // TODO: doc -2024-10-25
-func (c *Client) DeletePost(id string) error {
+func (c *Client) DeletePost(ctx context.Context, id string) error {
 	fullName := postFullName(id)
-	if err := c.deleteThing(fullName); err != nil {
+	if err := c.deleteThing(ctx, fullName); err != nil {
 		return fmt.Errorf("error deleting post with id %s: %w", fullName, err)
 	}
 	return nil
 }
 
 // TODO: doc -2024-10-30
-func (c *Client) unsaveThing(fullName string) error {
-	_, err := c.rc.R().
+func (c *Client) unsaveThing(ctx context.Context, fullName string) error {
+	resp, err := c.rc.R().
+		SetContext(ctx).
 		SetFormData(map[string]string{"id": fullName}).
 		Post("/api/unsave")
-	return err
+	if err != nil {
+		return err
+	}
+	return checkResponse(resp)
 }
 
 // TODO: doc -2024-10-25
-func (c *Client) deleteThing(fullName string) error {
-	_, err := c.rc.R().
+func (c *Client) deleteThing(ctx context.Context, fullName string) error {
+	resp, err := c.rc.R().
+		SetContext(ctx).
 		SetFormData(map[string]string{"id": fullName}).
 		Post("/api/del")
-	return err
+	if err != nil {
+		return err
+	}
+	return checkResponse(resp)
 }
@@ -0,0 +1,396 @@
+package reddit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient creates a Client backed by a test server. The server responds
+// to OAuth2 token requests itself, and delegates all other requests to the
+// given handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		"/api/v1/access_token", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "test_token", "token_type": "bearer", "expires_in": 3600}`))
+		},
+	)
+	mux.HandleFunc("/", handler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(
+		context.Background(), Config{
+			BaseURL:      server.URL,
+			ClientID:     "test_client_id",
+			ClientSecret: "test_client_secret",
+			Username:     "test_username",
+			Password:     "test_password",
+		},
+	)
+	require.NoError(t, err)
+	return client
+}
+
+func TestClient_GetSavedPosts(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/user/dummy/saved.json", r.URL.Path)
+			require.Equal(t, "links", r.URL.Query().Get("type"))
+			require.Equal(t, "t3_abc", r.URL.Query().Get("after"))
+			_, _ = w.Write(
+				[]byte(`{
+				"kind": "Listing",
+				"data": {
+					"after": "t3_def",
+					"children": [
+						{
+							"kind": "t3",
+							"data": {
+								"id": "def",
+								"title": "A saved post",
+								"permalink": "/r/golang/comments/def/a_saved_post/",
+								"subreddit": "golang",
+								"score": 42,
+								"created_utc": 1729911254.0
+							}
+						}
+					]
+				}
+			}`),
+			)
+		},
+	)
+
+	res, err := client.GetSavedPosts(context.Background(), "dummy", "t3_abc")
+	require.NoError(t, err)
+	require.Equal(t, "t3_def", res.Data.After)
+	posts := res.Items()
+	require.Len(t, posts, 1)
+	require.Equal(t, "def", posts[0].ID)
+	require.Equal(t, "A saved post", posts[0].Title)
+	require.Equal(t, 42, posts[0].Score)
+}
+
+func TestClient_UnsaveComment(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/unsave", r.URL.Path)
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "t1_abc", r.PostForm.Get("id"))
+			_, _ = w.Write([]byte(`{}`))
+		},
+	)
+
+	require.NoError(t, client.UnsaveComment(context.Background(), "abc"))
+}
+
+func TestClient_GetFriends(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/v1/me/friends", r.URL.Path)
+			_, _ = w.Write(
+				[]byte(`{
+				"kind": "UserList",
+				"data": {
+					"children": [
+						{"date": 1729911254.0, "rel_id": "r9_abc", "name": "friend1", "id": "t2_abc"},
+						{"date": 1729911255.0, "rel_id": "r9_def", "name": "friend2", "id": "t2_def"}
+					]
+				}
+			}`),
+			)
+		},
+	)
+
+	friends, err := client.GetFriends(context.Background())
+	require.NoError(t, err)
+	require.Len(t, friends, 2)
+	require.Equal(t, "friend1", friends[0].Name)
+	require.Equal(t, "t2_def", friends[1].ID)
+}
+
+func TestClient_Unfriend(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodDelete, r.Method)
+			require.Equal(t, "/api/v1/me/friends/friend1", r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		},
+	)
+
+	require.NoError(t, client.Unfriend(context.Background(), "friend1"))
+}
+
+func TestClient_GetComments_ListingOptions(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/user/dummy/comments.json", r.URL.Path)
+			require.Equal(t, "top", r.URL.Query().Get("sort"))
+			require.Equal(t, "year", r.URL.Query().Get("t"))
+			require.Equal(t, "t1_abc", r.URL.Query().Get("after"))
+			_, _ = w.Write([]byte(`{"kind": "Listing", "data": {"after": null, "children": []}}`))
+		},
+	)
+
+	res, err := client.GetComments(
+		context.Background(), "dummy", ListingOptions{After: "t1_abc", Sort: SortTop, Time: TimeWindowYear},
+	)
+	require.NoError(t, err)
+	require.Empty(t, res.Items())
+	require.Empty(t, res.Data.After)
+}
+
+func TestClient_RetriesWhenRateLimited(t *testing.T) {
+	requests := 0
+	client := newTestClient(
+		t, func(w http.ResponseWriter, _ *http.Request) {
+			requests++
+			if requests == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_, _ = w.Write([]byte(`{"kind": "Listing", "data": {"after": null, "children": []}}`))
+		},
+	)
+
+	_, err := client.GetPosts(context.Background(), "dummy", ListingOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 2, requests)
+}
+
+func TestClient_EditComment_RetriesWhenRateLimited(t *testing.T) {
+	requests := 0
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/editusertext", r.URL.Path)
+			requests++
+			if requests == 1 {
+				_, _ = w.Write(
+					[]byte(`{
+					"jquery": [
+						[1, 10, "attr", "find"],
+						[10, 11, "call", [".error.RATELIMIT.field-ratelimit"]],
+						[14, 15, "call", ["Take a break for 1 second before trying again."]]
+					],
+					"success": false
+				}`),
+				)
+				return
+			}
+			_, _ = w.Write([]byte(`{"jquery": [], "success": true}`))
+		},
+	)
+
+	require.NoError(t, client.EditComment(context.Background(), "abc", "[deleted]"))
+	require.Equal(t, 2, requests)
+}
+
+func TestClient_DeleteComment_Error(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message": "Forbidden", "error": 403}`))
+		},
+	)
+
+	err := client.DeleteComment(context.Background(), "abc")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+	require.Equal(t, "Forbidden", apiErr.Message)
+}
+
+func TestClient_GetMe(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/v1/me", r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": "abc123", "name": "dummy"}`))
+		},
+	)
+	account, err := client.GetMe(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &Account{ID: "abc123", Name: "dummy"}, account)
+}
+
+func TestClient_GetThingsByFullname(t *testing.T) {
+	var batches []string
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/info", r.URL.Path)
+			ids := r.URL.Query().Get("id")
+			batches = append(batches, ids)
+			w.Header().Set("Content-Type", "application/json")
+			if strings.HasPrefix(ids, "t1_0,") {
+				_, _ = w.Write([]byte(`{"data": {"children": [{"kind": "t1", "data": {"id": "0", "author": "[deleted]", "body": "[deleted]"}}]}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data": {"children": [{"kind": "t3", "data": {"id": "xyz", "title": "hello"}}]}}`))
+		},
+	)
+
+	fullnames := make([]Fullname, 0, 101)
+	ids := make([]string, 0, 100)
+	for i := range 100 {
+		fullnames = append(fullnames, Fullname{Kind: KindComment, ID: fmt.Sprint(i)})
+		ids = append(ids, fmt.Sprintf("t1_%d", i))
+	}
+	fullnames = append(fullnames, Fullname{Kind: KindPost, ID: "xyz"})
+	things, err := client.GetThingsByFullname(context.Background(), fullnames)
+	require.NoError(t, err)
+	require.Equal(t, []Comment{{ID: "0", Author: DeletedText, Body: DeletedText}}, things.Comments)
+	require.Equal(t, []Post{{ID: "xyz", Title: "hello"}}, things.Posts)
+	require.Equal(t, []string{strings.Join(ids, ","), "t3_xyz"}, batches)
+}
+
+func TestClient_GetThingsByFullname_Invalid(t *testing.T) {
+	client := newTestClient(
+		t, func(_ http.ResponseWriter, _ *http.Request) {
+			t.Fatal("unexpected request")
+		},
+	)
+	tests := []struct {
+		name     string
+		fullname Fullname
+	}{
+		{name: "message", fullname: Fullname{Kind: KindMessage, ID: "abc"}},
+		{name: "subreddit", fullname: Fullname{Kind: KindSubreddit, ID: "abc"}},
+		{name: "no kind", fullname: Fullname{ID: "abc"}},
+		{name: "no ID", fullname: Fullname{Kind: KindComment}},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := client.GetThingsByFullname(
+					context.Background(), []Fullname{{Kind: KindComment, ID: "abc"}, tt.fullname},
+				)
+				require.ErrorContains(t, err, "only comments and posts can be looked up")
+			},
+		)
+	}
+}
+
+func TestClient_UnvotePost(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/vote", r.URL.Path)
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "t3_abc", r.PostForm.Get("id"))
+			require.Equal(t, "0", r.PostForm.Get("dir"))
+			_, _ = w.Write([]byte(`{}`))
+		},
+	)
+	require.NoError(t, client.UnvotePost(context.Background(), "abc"))
+}
+
+func TestClient_GetUpvotedPosts(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/user/dummy/upvoted.json", r.URL.Path)
+			require.Equal(t, "t3_abc", r.URL.Query().Get("after"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"data": {"after": "t3_def", "children": [{"kind": "t3", "data": {"id": "def"}}]}}`))
+		},
+	)
+	listing, err := client.GetUpvotedPosts(context.Background(), "dummy", "t3_abc")
+	require.NoError(t, err)
+	require.Equal(t, []Post{{ID: "def"}}, listing.Items())
+	require.Equal(t, "t3_def", listing.Data.After)
+}
+
+func TestClient_UnhidePost(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/unhide", r.URL.Path)
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "t3_abc", r.PostForm.Get("id"))
+			_, _ = w.Write([]byte(`{}`))
+		},
+	)
+	require.NoError(t, client.UnhidePost(context.Background(), "abc"))
+}
+
+func TestClient_GetInboxMessages(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/message/inbox.json", r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(
+				[]byte(`{"data": {"after": "t1_def", "children": [` +
+					`{"kind": "t4", "data": {"id": "abc", "author": "someone", "subject": "hi"}}, ` +
+					`{"kind": "t1", "data": {"id": "def", "author": "someone"}}]}}`),
+			)
+		},
+	)
+	listing, err := client.GetInboxMessages(context.Background(), "")
+	require.NoError(t, err)
+	// The comment reply is left out.
+	require.Equal(t, []Message{{ID: "abc", Author: "someone", Subject: "hi"}}, listing.Items())
+	require.Equal(t, "t1_def", listing.Data.After)
+}
+
+func TestClient_DeleteMessage(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/del_msg", r.URL.Path)
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "t4_abc", r.PostForm.Get("id"))
+			_, _ = w.Write([]byte(`{}`))
+		},
+	)
+	require.NoError(t, client.DeleteMessage(context.Background(), "abc"))
+}
+
+func TestClient_GetSubscriptions(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/subreddits/mine/subscriber.json", r.URL.Path)
+			require.Equal(t, "100", r.URL.Query().Get("limit"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(
+				[]byte(`{"data": {"after": "t5_b", "children": [{"kind": "t5", "data": {"id": "b", "display_name": "golang", "url": "/r/golang/"}}]}}`),
+			)
+		},
+	)
+	listing, err := client.GetSubscriptions(context.Background(), "")
+	require.NoError(t, err)
+	require.Equal(t, []Subreddit{{ID: "b", DisplayName: "golang", URL: "/r/golang/"}}, listing.Items())
+	require.Equal(t, "t5_b", listing.Data.After)
+}
+
+func TestClient_Unsubscribe(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/subscribe", r.URL.Path)
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "unsub", r.PostForm.Get("action"))
+			require.Equal(t, "t5_a,t5_b", r.PostForm.Get("sr"))
+			_, _ = w.Write([]byte(`{}`))
+		},
+	)
+	require.NoError(t, client.Unsubscribe(context.Background(), []string{"a", "b"}))
+}
+
+func TestClient_EditPost(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/editusertext", r.URL.Path)
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "t3_abc", r.PostForm.Get("thing_id"))
+			require.Equal(t, "[deleted]", r.PostForm.Get("text"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"jquery": [], "success": true}`))
+		},
+	)
+	require.NoError(t, client.EditPost(context.Background(), "abc", "[deleted]"))
+}
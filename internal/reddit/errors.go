@@ -0,0 +1,126 @@
+package reddit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// rateLimitErrorCode is the error code that Reddit uses for rate limiting
+// errors in response bodies.
+const rateLimitErrorCode = "RATELIMIT"
+
+// APIError is an error returned by Reddit's API, either as a non-2xx response
+// or as a list of errors in the body of an otherwise successful response.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Code is Reddit's error code, e.g. "RATELIMIT", if it provided one.
+	Code string
+	// Message is a human-readable description of the error.
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("reddit API error (status %d, %s): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("reddit API error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// Is reports whether the error is ErrRateLimited, so that rate limiting errors
+// can be checked with errors.Is regardless of how Reddit reported them.
+func (e *APIError) Is(target error) bool {
+	return target == ErrRateLimited &&
+		(e.StatusCode == http.StatusTooManyRequests || e.Code == rateLimitErrorCode)
+}
+
+// errorBody covers the various shapes of error bodies that Reddit returns.
+// Errors from the newer API endpoints look like
+// {"message": "Forbidden", "error": 403}, or sometimes
+// {"reason": "...", "explanation": "..."}, while the older endpoints return
+// {"json": {"errors": [["CODE", "message", "field"]]}}.
+type errorBody struct {
+	Message     string `json:"message"`
+	Error       any    `json:"error"`
+	Reason      string `json:"reason"`
+	Explanation string `json:"explanation"`
+	JSON        struct {
+		Errors [][]any `json:"errors"`
+	} `json:"json"`
+}
+
+// checkResponse returns an *APIError if the response has a non-2xx status code
+// or a body containing errors, and nil otherwise.
+func checkResponse(resp *resty.Response) error {
+	var body errorBody
+	// The body might not be JSON at all (e.g. an HTML error page), in which
+	// case we just go by the status code.
+	_ = json.Unmarshal(resp.Body(), &body)
+	if errs := body.JSON.Errors; len(errs) > 0 {
+		// Only the first error is reported; there is rarely more than one.
+		apiErr := &APIError{StatusCode: resp.StatusCode()}
+		if len(errs[0]) > 0 {
+			apiErr.Code, _ = errs[0][0].(string)
+		}
+		if len(errs[0]) > 1 {
+			apiErr.Message, _ = errs[0][1].(string)
+		}
+		return apiErr
+	}
+	if resp.IsSuccess() {
+		return nil
+	}
+	apiErr := &APIError{StatusCode: resp.StatusCode()}
+	switch {
+	case body.Reason != "":
+		apiErr.Code = body.Reason
+		apiErr.Message = body.Explanation
+	case body.Message != "":
+		apiErr.Message = body.Message
+	}
+	if code, ok := body.Error.(string); ok {
+		apiErr.Code = code
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode())
+	}
+	return apiErr
+}
+
+// editError returns an *APIError describing a failed edit. Errors from
+// /api/editusertext are described by the "jquery" commands in the response
+// (see EditResponse): a "call" with a selector like ".error.CODE.field-name",
+// followed later by a "text" call with the message.
+func (resp *EditResponse) editError(statusCode int) *APIError {
+	apiErr := &APIError{StatusCode: statusCode, Message: "edit failed"}
+	textNext := false
+	for _, elem := range resp.JQuery {
+		arr, ok := elem.([]any)
+		if !ok || len(arr) < 4 {
+			continue
+		}
+		switch arr[2] {
+		case "attr":
+			textNext = arr[3] == "text"
+		case "call":
+			args, ok := arr[3].([]any)
+			if !ok || len(args) != 1 {
+				continue
+			}
+			arg, ok := args[0].(string)
+			if !ok {
+				continue
+			}
+			if textNext && arg != "" {
+				apiErr.Message = arg
+			} else if code, ok := strings.CutPrefix(arg, ".error."); ok {
+				apiErr.Code, _, _ = strings.Cut(code, ".")
+			}
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,93 @@
+package reddit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		expected   *APIError
+	}{
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			body:       `{}`,
+		},
+		{
+			name:       "success with errors in body",
+			statusCode: http.StatusOK,
+			body:       `{"json": {"errors": [["RATELIMIT", "you are doing that too much", "ratelimit"]]}}`,
+			expected: &APIError{
+				StatusCode: http.StatusOK,
+				Code:       "RATELIMIT",
+				Message:    "you are doing that too much",
+			},
+		},
+		{
+			name:       "error with message",
+			statusCode: http.StatusForbidden,
+			body:       `{"message": "Forbidden", "error": 403}`,
+			expected:   &APIError{StatusCode: http.StatusForbidden, Message: "Forbidden"},
+		},
+		{
+			name:       "error with reason",
+			statusCode: http.StatusNotFound,
+			body:       `{"reason": "USER_DOESNT_EXIST", "explanation": "that user doesn't exist"}`,
+			expected: &APIError{
+				StatusCode: http.StatusNotFound,
+				Code:       "USER_DOESNT_EXIST",
+				Message:    "that user doesn't exist",
+			},
+		},
+		{
+			name:       "error with HTML body",
+			statusCode: http.StatusInternalServerError,
+			body:       `<html><body>Oops</body></html>`,
+			expected:   &APIError{StatusCode: http.StatusInternalServerError, Message: "Internal Server Error"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				resp := &resty.Response{RawResponse: &http.Response{StatusCode: tt.statusCode}}
+				resp.SetBody([]byte(tt.body))
+				err := checkResponse(resp)
+				if tt.expected == nil {
+					require.NoError(t, err)
+					return
+				}
+				var apiErr *APIError
+				require.ErrorAs(t, err, &apiErr)
+				require.Equal(t, tt.expected, apiErr)
+			},
+		)
+	}
+}
+
+func TestAPIError_IsRateLimited(t *testing.T) {
+	require.ErrorIs(t, &APIError{StatusCode: http.StatusTooManyRequests}, ErrRateLimited)
+	require.ErrorIs(t, &APIError{StatusCode: http.StatusOK, Code: "RATELIMIT"}, ErrRateLimited)
+	require.False(t, errors.Is(&APIError{StatusCode: http.StatusForbidden}, ErrRateLimited))
+}
+
+func TestEditResponse_editError(t *testing.T) {
+	var resp EditResponse
+	require.NoError(t, json.Unmarshal([]byte(editRateLimitErrorBody), &resp))
+	apiErr := resp.editError(http.StatusOK)
+	require.Equal(t, "RATELIMIT", apiErr.Code)
+	require.Equal(
+		t,
+		"Looks like you've been doing that a lot. Take a break for 3 seconds before trying again.",
+		apiErr.Message,
+	)
+	require.ErrorIs(t, apiErr, ErrRateLimited)
+}
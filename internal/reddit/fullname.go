@@ -1,19 +1,64 @@
 package reddit
 
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind is the type of a Reddit "thing", which prefixes its fullname.
+type Kind string
+
 const (
 	// Reddit "things" (e.g. comments, posts) have "fullnames", which are
-	// unique identifiers constructed as a prefix followed by some opaque
+	// unique identifiers constructed as a kind prefix followed by some opaque
 	// ID string. See https://www.reddit.com/dev/api/#fullnames.
-	commentPrefix = "t1_"
-	postPrefix    = "t3_"
+	KindComment   Kind = "t1"
+	KindPost      Kind = "t3"
+	KindMessage   Kind = "t4"
+	KindSubreddit Kind = "t5"
 )
 
+// Fullname is the unique identifier of a Reddit thing, e.g. "t1_abc123".
+type Fullname struct {
+	Kind Kind
+	ID   string
+}
+
+// ParseFullname parses a fullname, which must be of a kind that shreddit
+// knows about.
+func ParseFullname(s string) (Fullname, error) {
+	kind, id, ok := strings.Cut(s, "_")
+	if !ok || id == "" {
+		return Fullname{}, fmt.Errorf("invalid fullname %q", s)
+	}
+	switch Kind(kind) {
+	case KindComment, KindPost, KindMessage, KindSubreddit:
+	default:
+		return Fullname{}, fmt.Errorf("invalid fullname %q: unsupported kind %q", s, kind)
+	}
+	return Fullname{Kind: Kind(kind), ID: id}, nil
+}
+
+func (f Fullname) String() string {
+	return string(f.Kind) + "_" + f.ID
+}
+
 // commentFullName returns the fullname of a comment given its ID.
 func commentFullName(id string) string {
-	return commentPrefix + id
+	return Fullname{Kind: KindComment, ID: id}.String()
 }
 
 // postFullName returns the fullname of a post given its ID.
 func postFullName(id string) string {
-	return postPrefix + id
+	return Fullname{Kind: KindPost, ID: id}.String()
+}
+
+// messageFullName returns the fullname of a private message given its ID.
+func messageFullName(id string) string {
+	return Fullname{Kind: KindMessage, ID: id}.String()
+}
+
+// subredditFullName returns the fullname of a subreddit given its ID.
+func subredditFullName(id string) string {
+	return Fullname{Kind: KindSubreddit, ID: id}.String()
 }
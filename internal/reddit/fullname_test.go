@@ -0,0 +1,37 @@
+package reddit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFullname(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Fullname
+		wantErr bool
+	}{
+		{name: "comment", s: "t1_abc123", want: Fullname{Kind: KindComment, ID: "abc123"}},
+		{name: "post", s: "t3_xyz", want: Fullname{Kind: KindPost, ID: "xyz"}},
+		{name: "message", s: "t4_def", want: Fullname{Kind: KindMessage, ID: "def"}},
+		{name: "no prefix", s: "abc123", wantErr: true},
+		{name: "no ID", s: "t1_", wantErr: true},
+		{name: "unsupported kind", s: "t2_abc123", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := ParseFullname(tt.s)
+				if tt.wantErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tt.want, got)
+				require.Equal(t, tt.s, got.String())
+			},
+		)
+	}
+}
@@ -4,50 +4,221 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/cenkalti/backoff/v5"
 	"golang.org/x/oauth2"
 )
 
 // NewOAuth2Client creates and configures an OAuth2 HTTP client with retry logic
-// for rate limiting.
+// for rate limiting. If cfg.TokenFile is set, the client uses the token saved
+// there (see Authorize), and otherwise it uses the password grant.
 func NewOAuth2Client(ctx context.Context, cfg Config) (*http.Client, error) {
-	// Create a custom HTTP client with the User-Agent header, used for OAuth2
-	// token requests.
-	// Ref: https://github.com/golang/oauth2/issues/179
+	// The context is used to get new tokens for the lifetime of the client,
+	// so it must not be cancelled along with the context used to create the
+	// client.
+	ctx = oauthContext(context.WithoutCancel(ctx), cfg)
+	oauthCfg := newOAuthConfig(cfg, "")
+
+	var (
+		src oauth2.TokenSource
+		tok *oauth2.Token
+	)
+	if cfg.TokenFile != "" {
+		saved, err := LoadToken(cfg.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		src = &fileTokenSource{
+			ctx:          ctx,
+			oauthCfg:     oauthCfg,
+			path:         cfg.TokenFile,
+			refreshToken: saved.RefreshToken,
+		}
+		// Use the saved access token until it expires.
+		if !expiresSoon(saved) {
+			tok = saved
+		}
+	} else {
+		pwSrc := &passwordTokenSource{
+			ctx:      ctx,
+			oauthCfg: oauthCfg,
+			username: cfg.Username,
+			password: cfg.Password,
+		}
+		if cfg.TOTPSecret != "" {
+			var err error
+			if pwSrc.totp, err = newTOTP(cfg.TOTPSecret); err != nil {
+				return nil, err
+			}
+		}
+		src = pwSrc
+	}
+
+	// Get the initial token.
+	if tok == nil {
+		var err error
+		if tok, err = src.Token(); err != nil {
+			return nil, fmt.Errorf("error getting initial token: %w", err)
+		}
+	}
+
+	// Create a new OAuth2 client with the initial token, which gets a new one
+	// from src when it's about to expire or is rejected.
+	transport := &reauthTransport{
+		base: &userAgentTransport{base: http.DefaultTransport, userAgent: cfg.UserAgent},
+		src:  src,
+		tok:  tok,
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// tokenExpiryDelta is how long before a token expires that it's replaced, so
+// that requests don't race its expiry.
+const tokenExpiryDelta = time.Minute
+
+// expiresSoon reports whether the token has expired or will within
+// tokenExpiryDelta.
+func expiresSoon(tok *oauth2.Token) bool {
+	if tok.AccessToken == "" {
+		return true
+	}
+	return !tok.Expiry.IsZero() && time.Until(tok.Expiry) < tokenExpiryDelta
+}
+
+// reauthTransport authorizes requests with tokens from src. A new token is
+// fetched when the current one is about to expire, or when a request is
+// rejected with a 401 (e.g. because the token was revoked, or expired early),
+// in which case the request is retried once with the new token.
+type reauthTransport struct {
+	base http.RoundTripper
+	src  oauth2.TokenSource
+	mu   sync.Mutex
+	tok  *oauth2.Token
+}
+
+func (t *reauthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tok, err := t.token(nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(authorize(req, tok))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	// The request can only be retried if its body can be read again.
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+	slog.Warn("Request unauthorized; getting a new token and retrying", "url", req.URL.Redacted())
+	tok, err = t.token(tok)
+	if err != nil {
+		// Report the original response rather than the failure to recover.
+		return resp, nil
+	}
+	retry := authorize(req, tok)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return t.base.RoundTrip(retry)
+}
+
+// token returns the current token, or a new one if it's about to expire or is
+// the given rejected token. Concurrent requests rejected with the same token
+// only cause a single new token to be fetched.
+func (t *reauthTransport) token(rejected *oauth2.Token) (*oauth2.Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tok != nil && t.tok != rejected && !expiresSoon(t.tok) {
+		return t.tok, nil
+	}
+	tok, err := t.src.Token()
+	if err != nil {
+		return nil, fmt.Errorf("error getting token: %w", err)
+	}
+	t.tok = tok
+	return tok, nil
+}
+
+// authorize returns a copy of the request with the token's Authorization
+// header. As required of a RoundTripper, the original is left untouched.
+func authorize(req *http.Request, tok *oauth2.Token) *http.Request {
+	req = req.Clone(req.Context())
+	tok.SetAuthHeader(req)
+	return req
+}
+
+// oauthContext returns a context for the oauth2 package to use, which makes
+// its token requests with the configured User-Agent header.
+// Ref: https://github.com/golang/oauth2/issues/179
+func oauthContext(ctx context.Context, cfg Config) context.Context {
 	client := &http.Client{
 		Transport: &userAgentTransport{
 			base:      http.DefaultTransport,
 			userAgent: cfg.UserAgent,
 		},
 	}
-	ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
+	return context.WithValue(ctx, oauth2.HTTPClient, client)
+}
 
-	oauthCfg := &oauth2.Config{
+// newOAuthConfig returns the OAuth2 config for Reddit. The redirect URL is
+// only needed for the authorization code flow. No scopes are set, so that the
+// password grant gets a token with full access, as it always has; Authorize
+// sets the scopes that it asks the user for.
+func newOAuthConfig(cfg Config, redirectURL string) *oauth2.Config {
+	return &oauth2.Config{
 		ClientID:     cfg.ClientID,
 		ClientSecret: cfg.ClientSecret,
 		Endpoint: oauth2.Endpoint{
+			AuthURL:   authURL,
 			TokenURL:  fmt.Sprintf("%s/api/v1/access_token", cfg.BaseURL),
 			AuthStyle: oauth2.AuthStyleInHeader,
 		},
+		RedirectURL: redirectURL,
 	}
+}
 
-	// Get the initial token.
-	tok, err := getToken(ctx, oauthCfg, cfg.Username, cfg.Password)
-	if err != nil {
-		return nil, fmt.Errorf("error getting initial token: %w", err)
-	}
+// passwordTokenSource gets tokens with the password grant. Reddit doesn't issue
+// refresh tokens for this grant, so it is repeated whenever a token expires.
+type passwordTokenSource struct {
+	ctx      context.Context
+	oauthCfg *oauth2.Config
+	username string
+	password string
+	// totp, if set, generates a code for the account's two-factor
+	// authentication, which Reddit expects to be appended to the password
+	// (e.g. "hunter2:123456"). A new code is generated for each token.
+	totp *totp
+}
 
-	// Create a new OAuth2 client with the initial token.
-	return oauthCfg.Client(ctx, tok), nil
+func (s *passwordTokenSource) Token() (*oauth2.Token, error) {
+	return getToken(s.ctx, s.oauthCfg, s.username, s.passwordWithCode)
+}
+
+// passwordWithCode returns the password, with a current TOTP code appended if
+// needed.
+func (s *passwordTokenSource) passwordWithCode() string {
+	if s.totp == nil {
+		return s.password
+	}
+	return s.password + ":" + s.totp.code()
 }
 
 // getToken attempts to get an OAuth2 token with retry logic for rate limiting.
-func getToken(ctx context.Context, oauthCfg *oauth2.Config, username, password string) (*oauth2.Token, error) {
+// The password is fetched for each attempt, since it may include a TOTP code
+// which expires while waiting to retry.
+func getToken(ctx context.Context, oauthCfg *oauth2.Config, username string, password func() string) (*oauth2.Token, error) {
 	operation := func() (*oauth2.Token, error) {
-		tok, err := oauthCfg.PasswordCredentialsToken(ctx, username, password)
+		tok, err := oauthCfg.PasswordCredentialsToken(ctx, username, password())
 		if err != nil {
 			var retrieveError *oauth2.RetrieveError
 			if errors.As(err, &retrieveError) {
@@ -2,9 +2,11 @@ package reddit
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -108,6 +110,184 @@ func TestNewOAuth2Client(t *testing.T) {
 	}
 }
 
+func TestNewOAuth2Client_TOTP(t *testing.T) {
+	var passwords []string
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v1/access_token" {
+					require.NoError(t, r.ParseForm())
+					passwords = append(passwords, r.PostForm.Get("password"))
+					w.Header().Set("Content-Type", "application/json")
+					// Expire immediately, so that every request needs a new
+					// token.
+					_, _ = w.Write([]byte(`{"access_token": "test_token", "token_type": "bearer", "expires_in": 1}`))
+					return
+				}
+				require.Equal(t, "Bearer test_token", r.Header.Get("Authorization"))
+				w.WriteHeader(http.StatusOK)
+			},
+		),
+	)
+	defer server.Close()
+
+	cfg := Config{
+		BaseURL:      server.URL,
+		ClientID:     "test_client_id",
+		ClientSecret: "test_client_secret",
+		Username:     "test_username",
+		Password:     "test_password",
+		UserAgent:    "TestUserAgent",
+		TOTPSecret:   "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+	}
+	client, err := NewOAuth2Client(context.Background(), cfg)
+	require.NoError(t, err)
+	resp, err := client.Get(server.URL + "/api/v1/me")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	// The password grant is repeated for the expired token, with a code
+	// appended to the password each time.
+	require.Len(t, passwords, 2)
+	for _, password := range passwords {
+		require.Regexp(t, `^test_password:\d{6}$`, password)
+	}
+}
+
+func TestPasswordTokenSource_NewCodeForEachRetry(t *testing.T) {
+	var passwords []string
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, r.ParseForm())
+				passwords = append(passwords, r.PostForm.Get("password"))
+				if len(passwords) == 1 {
+					w.Header().Set("Retry-After", "1")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"access_token": "test_token", "token_type": "bearer", "expires_in": 3600}`))
+			},
+		),
+	)
+	defer server.Close()
+
+	codes, err := newTOTP("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+	require.NoError(t, err)
+	// Each code is generated in a later 30 second window than the last.
+	now := time.Unix(59, 0)
+	codes.now = func() time.Time {
+		now = now.Add(30 * time.Second)
+		return now
+	}
+	src := &passwordTokenSource{
+		ctx:      context.Background(),
+		oauthCfg: newOAuthConfig(Config{BaseURL: server.URL, ClientID: "test_client_id"}, ""),
+		username: "test_username",
+		password: "test_password",
+		totp:     codes,
+	}
+	_, err = src.Token()
+	require.NoError(t, err)
+	require.Len(t, passwords, 2)
+	require.NotEqual(t, passwords[0], passwords[1])
+	require.Equal(t, "test_password:"+totpCode(codes.key, now), passwords[1])
+}
+
+func TestNewOAuth2Client_PasswordGrantRequestsNoScope(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, r.ParseForm())
+				require.Equal(t, "password", r.PostForm.Get("grant_type"))
+				// Without a scope, the token has full access.
+				require.NotContains(t, r.PostForm, "scope")
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"access_token": "test_token", "token_type": "bearer", "expires_in": 3600}`))
+			},
+		),
+	)
+	defer server.Close()
+
+	_, err := NewOAuth2Client(
+		context.Background(), Config{
+			BaseURL:  server.URL,
+			ClientID: "test_client_id",
+			Username: "test_username",
+			Password: "test_password",
+		},
+	)
+	require.NoError(t, err)
+}
+
+func TestNewOAuth2Client_InvalidTOTPSecret(t *testing.T) {
+	_, err := NewOAuth2Client(context.Background(), Config{TOTPSecret: "!"})
+	require.ErrorContains(t, err, "invalid TOTP secret")
+}
+
+func TestNewClient_Reauthenticates(t *testing.T) {
+	tests := []struct {
+		name string
+		// expiresIn is the lifetime of each token.
+		expiresIn int
+		// revoke makes the API reject the first token.
+		revoke     bool
+		wantTokens int
+	}{
+		{name: "valid token", expiresIn: 3600, wantTokens: 1},
+		{name: "token rejected", expiresIn: 3600, revoke: true, wantTokens: 2},
+		{name: "token near expiry", expiresIn: 30, wantTokens: 2},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tokens := 0
+				mux := http.NewServeMux()
+				mux.HandleFunc(
+					"/api/v1/access_token", func(w http.ResponseWriter, _ *http.Request) {
+						tokens++
+						w.Header().Set("Content-Type", "application/json")
+						_, _ = fmt.Fprintf(
+							w, `{"access_token": "token_%d", "token_type": "bearer", "expires_in": %d}`,
+							tokens, tt.expiresIn,
+						)
+					},
+				)
+				mux.HandleFunc(
+					"/api/unsave", func(w http.ResponseWriter, r *http.Request) {
+						if tt.revoke && r.Header.Get("Authorization") == "Bearer token_1" {
+							w.WriteHeader(http.StatusUnauthorized)
+							_, _ = w.Write([]byte(`{"message": "Unauthorized", "error": 401}`))
+							return
+						}
+						// The retried request has the same body.
+						require.NoError(t, r.ParseForm())
+						require.Equal(t, "t1_abc123", r.PostForm.Get("id"))
+						w.WriteHeader(http.StatusOK)
+						_, _ = w.Write([]byte(`{}`))
+					},
+				)
+				server := httptest.NewServer(mux)
+				defer server.Close()
+
+				client, err := NewClient(
+					context.Background(), Config{
+						BaseURL:      server.URL,
+						ClientID:     "test_client_id",
+						ClientSecret: "test_client_secret",
+						Username:     "test_username",
+						Password:     "test_password",
+					},
+				)
+				require.NoError(t, err)
+				require.NoError(t, client.UnsaveComment(context.Background(), "abc123"))
+				require.Equal(t, tt.wantTokens, tokens)
+			},
+		)
+	}
+}
+
 func TestUserAgentTransport(t *testing.T) {
 	server := httptest.NewServer(
 		http.HandlerFunc(
@@ -0,0 +1,156 @@
+package reddit
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Reddit includes these headers in every API response to describe the state of
+// the client's rate limit.
+const (
+	headerRateLimitRemaining = "X-Ratelimit-Remaining"
+	headerRateLimitUsed      = "X-Ratelimit-Used"
+	headerRateLimitReset     = "X-Ratelimit-Reset"
+	headerRetryAfter         = "Retry-After"
+
+	// maxRateLimitRetries is the maximum number of times that a request is
+	// retried when Reddit says that we are being rate limited.
+	maxRateLimitRetries = 5
+
+	// defaultRateLimitWait is how long to wait after being rate limited if
+	// Reddit doesn't say how long to wait.
+	defaultRateLimitWait = 10 * time.Second
+)
+
+// rateLimitWaitPattern matches the wait time in Reddit's rate limit error
+// messages, e.g. "Take a break for 3 seconds before trying again."
+var rateLimitWaitPattern = regexp.MustCompile(`(\d+) (second|minute)s?`)
+
+// rateLimiter paces requests according to the rate limit headers in Reddit's
+// responses. It spreads the remaining requests in the current rate limit
+// window evenly over the time left until the window resets, and blocks all
+// requests entirely when the limit is exhausted or Reddit tells us to back
+// off. It is safe for concurrent use.
+type rateLimiter struct {
+	mu sync.Mutex
+	// interval is the minimum time between the start of two requests.
+	interval time.Duration
+	// next is the earliest time that the next request may start.
+	next time.Time
+	// blockedUntil is the time until which no requests may start.
+	blockedUntil time.Time
+	// now returns the current time. It is overridden in tests.
+	now func() time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{now: time.Now}
+}
+
+// wait blocks until the next request may be sent, or the context is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve reserves a slot for a request, and returns how long the caller must
+// wait before sending it.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	start := now
+	if l.next.After(start) {
+		start = l.next
+	}
+	if l.blockedUntil.After(start) {
+		start = l.blockedUntil
+	}
+	l.next = start.Add(l.interval)
+	return start.Sub(now)
+}
+
+// update adjusts the pace of requests based on the rate limit headers of a
+// response. Responses without the headers are ignored.
+func (l *rateLimiter) update(header http.Header) {
+	remaining, err := strconv.ParseFloat(header.Get(headerRateLimitRemaining), 64)
+	if err != nil {
+		return
+	}
+	resetSeconds, err := strconv.Atoi(header.Get(headerRateLimitReset))
+	if err != nil {
+		return
+	}
+	reset := time.Duration(resetSeconds) * time.Second
+	slog.Debug(
+		"Rate limit status",
+		"used", header.Get(headerRateLimitUsed),
+		"remaining", remaining,
+		"reset", reset,
+	)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if remaining < 1 {
+		// Out of requests; nothing can be sent until the window resets.
+		l.blockedUntil = l.now().Add(reset)
+		l.interval = 0
+		return
+	}
+	l.interval = time.Duration(float64(reset) / remaining)
+}
+
+// block prevents any requests from starting until the given duration has
+// elapsed.
+func (l *rateLimiter) block(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	until := l.now().Add(d)
+	if until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+// retryAfter returns how long to wait before retrying a request whose
+// response was rate limited (HTTP 429), based on the response's headers.
+func retryAfter(header http.Header) time.Duration {
+	for _, name := range []string{headerRetryAfter, headerRateLimitReset} {
+		if seconds, err := strconv.Atoi(header.Get(name)); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return defaultRateLimitWait
+}
+
+// parseRateLimitWait parses the wait time out of one of Reddit's rate limit
+// error messages. If the message doesn't contain a wait time,
+// defaultRateLimitWait is returned.
+func parseRateLimitWait(msg string) time.Duration {
+	match := rateLimitWaitPattern.FindStringSubmatch(msg)
+	if match == nil {
+		return defaultRateLimitWait
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil {
+		return defaultRateLimitWait
+	}
+	if match[2] == "minute" {
+		return time.Duration(n) * time.Minute
+	}
+	return time.Duration(n) * time.Second
+}
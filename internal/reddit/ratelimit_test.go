@@ -0,0 +1,60 @@
+package reddit
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2024, 10, 30, 0, 0, 0, 0, time.UTC)
+	l := newRateLimiter()
+	l.now = func() time.Time { return now }
+
+	// No rate limit information yet; requests aren't delayed.
+	require.Zero(t, l.reserve())
+	require.Zero(t, l.reserve())
+
+	// 10 requests remaining in the next 100 seconds; requests should be
+	// spaced 10 seconds apart.
+	l.update(rateLimitHeader("10.0", "90", "100"))
+	require.Zero(t, l.reserve())
+	require.Equal(t, 10*time.Second, l.reserve())
+	require.Equal(t, 20*time.Second, l.reserve())
+
+	// Out of requests; nothing until the window resets.
+	l.next = time.Time{}
+	l.update(rateLimitHeader("0", "100", "30"))
+	require.Equal(t, 30*time.Second, l.reserve())
+
+	// Explicitly blocked for longer.
+	l.block(time.Minute)
+	require.Equal(t, time.Minute, l.reserve())
+
+	// Responses without rate limit headers don't change anything.
+	l.update(http.Header{})
+	require.Equal(t, time.Minute, l.reserve())
+}
+
+func TestRetryAfter(t *testing.T) {
+	require.Equal(t, 5*time.Second, retryAfter(http.Header{"Retry-After": []string{"5"}}))
+	require.Equal(t, 30*time.Second, retryAfter(rateLimitHeader("0", "100", "30")))
+	require.Equal(t, defaultRateLimitWait, retryAfter(http.Header{}))
+}
+
+func TestParseRateLimitWait(t *testing.T) {
+	require.Equal(t, 3*time.Second, parseRateLimitWait(editRateLimitErrorBody))
+	require.Equal(t, 9*time.Minute, parseRateLimitWait("Take a break for 9 minutes before trying again."))
+	require.Equal(t, time.Second, parseRateLimitWait("Take a break for 1 second before trying again."))
+	require.Equal(t, defaultRateLimitWait, parseRateLimitWait("Take a break."))
+}
+
+func rateLimitHeader(remaining, used, reset string) http.Header {
+	h := http.Header{}
+	h.Set(headerRateLimitRemaining, remaining)
+	h.Set(headerRateLimitUsed, used)
+	h.Set(headerRateLimitReset, reset)
+	return h
+}
@@ -0,0 +1,94 @@
+package reddit
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// endpointScopes are the OAuth2 scopes that Reddit requires for each endpoint
+// that the client calls, keyed by method and path (with any ".json" suffix
+// removed). See https://www.reddit.com/dev/api/oauth.
+var endpointScopes = map[string]string{
+	"GET /user/dummy/submitted":       "history",
+	"GET /user/dummy/comments":        "history",
+	"GET /user/dummy/saved":           "history",
+	"GET /user/dummy/upvoted":         "history",
+	"GET /user/dummy/downvoted":       "history",
+	"GET /user/dummy/hidden":          "history",
+	"GET /message/inbox":              "privatemessages",
+	"GET /message/sent":               "privatemessages",
+	"GET /subreddits/mine/subscriber": "mysubreddits",
+	"GET /api/v1/me/friends":          "mysubreddits",
+	"DELETE /api/v1/me/friends/dummy": "subscribe",
+	"GET /api/info":                   "read",
+	"GET /api/v1/me":                  "identity",
+	"POST /api/editusertext":          "edit",
+	"POST /api/del":                   "edit",
+	"POST /api/unsave":                "save",
+	"POST /api/vote":                  "vote",
+	"POST /api/unhide":                "report",
+	"POST /api/del_msg":               "privatemessages",
+	"POST /api/subscribe":             "subscribe",
+}
+
+func TestScopes_CoverEveryEndpoint(t *testing.T) {
+	ctx := context.Background()
+	// calls calls each of the client's API methods.
+	calls := map[string]func(c *Client) error{
+		"GetPosts":          func(c *Client) error { _, err := c.GetPosts(ctx, "dummy", ListingOptions{}); return err },
+		"GetComments":       func(c *Client) error { _, err := c.GetComments(ctx, "dummy", ListingOptions{}); return err },
+		"GetSavedPosts":     func(c *Client) error { _, err := c.GetSavedPosts(ctx, "dummy", ""); return err },
+		"GetSavedComments":  func(c *Client) error { _, err := c.GetSavedComments(ctx, "dummy", ""); return err },
+		"GetUpvotedPosts":   func(c *Client) error { _, err := c.GetUpvotedPosts(ctx, "dummy", ""); return err },
+		"GetDownvotedPosts": func(c *Client) error { _, err := c.GetDownvotedPosts(ctx, "dummy", ""); return err },
+		"GetHiddenPosts":    func(c *Client) error { _, err := c.GetHiddenPosts(ctx, "dummy", ""); return err },
+		"GetInboxMessages":  func(c *Client) error { _, err := c.GetInboxMessages(ctx, ""); return err },
+		"GetSentMessages":   func(c *Client) error { _, err := c.GetSentMessages(ctx, ""); return err },
+		"GetSubscriptions":  func(c *Client) error { _, err := c.GetSubscriptions(ctx, ""); return err },
+		"GetFriends":        func(c *Client) error { _, err := c.GetFriends(ctx); return err },
+		"Unfriend":          func(c *Client) error { return c.Unfriend(ctx, "dummy") },
+		"GetThingsByFullname": func(c *Client) error {
+			_, err := c.GetThingsByFullname(ctx, []Fullname{{Kind: KindComment, ID: "abc"}})
+			return err
+		},
+		"GetMe":         func(c *Client) error { _, err := c.GetMe(ctx); return err },
+		"EditComment":   func(c *Client) error { return c.EditComment(ctx, "abc", "text") },
+		"EditPost":      func(c *Client) error { return c.EditPost(ctx, "abc", "text") },
+		"UnsaveComment": func(c *Client) error { return c.UnsaveComment(ctx, "abc") },
+		"UnsavePost":    func(c *Client) error { return c.UnsavePost(ctx, "abc") },
+		"UnvotePost":    func(c *Client) error { return c.UnvotePost(ctx, "abc") },
+		"UnhidePost":    func(c *Client) error { return c.UnhidePost(ctx, "abc") },
+		"DeleteMessage": func(c *Client) error { return c.DeleteMessage(ctx, "abc") },
+		"Unsubscribe":   func(c *Client) error { return c.Unsubscribe(ctx, []string{"abc"}) },
+		"DeleteComment": func(c *Client) error { return c.DeleteComment(ctx, "abc") },
+		"DeletePost":    func(c *Client) error { return c.DeletePost(ctx, "abc") },
+	}
+	// Make sure that new methods are added to calls.
+	clientType := reflect.TypeOf(&Client{})
+	for i := range clientType.NumMethod() {
+		name := clientType.Method(i).Name
+		require.Contains(t, calls, name, "method %s isn't covered by this test", name)
+	}
+
+	var endpoints []string
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			endpoints = append(endpoints, r.Method+" "+strings.TrimSuffix(r.URL.Path, ".json"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"success": true}`))
+		},
+	)
+	for name, call := range calls {
+		require.NoError(t, call(client), name)
+	}
+	for _, endpoint := range endpoints {
+		scope, ok := endpointScopes[endpoint]
+		require.True(t, ok, "no scope is known for %s", endpoint)
+		require.Contains(t, scopes, scope, "scope %s for %s isn't requested", scope, endpoint)
+	}
+}
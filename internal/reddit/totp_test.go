@@ -0,0 +1,41 @@
+package reddit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// Test vectors for SHA-1 from RFC 6238, appendix B, truncated to 6 digits.
+	key := []byte("12345678901234567890")
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, totpCode(key, time.Unix(tt.unix, 0)), tt.unix)
+	}
+}
+
+func TestNewTOTP(t *testing.T) {
+	// Base32 of "12345678901234567890", formatted as authenticator apps often
+	// show it.
+	tp, err := newTOTP("gezd gnbv gy3t qojq gezd gnbv gy3t qojq")
+	require.NoError(t, err)
+	tp.now = func() time.Time { return time.Unix(59, 0) }
+	require.Equal(t, "287082", tp.code())
+
+	_, err = newTOTP("not base32!")
+	require.ErrorContains(t, err, "invalid TOTP secret")
+	_, err = newTOTP("")
+	require.ErrorContains(t, err, "invalid TOTP secret")
+}
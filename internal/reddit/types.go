@@ -6,19 +6,28 @@ import (
 	"time"
 )
 
-const rateLimitErrorText = ".error.RATELIMIT.field-ratelimit"
+const (
+	rateLimitErrorText = ".error.RATELIMIT.field-ratelimit"
+	// DeletedText is what Reddit shows instead of the author and body of
+	// deleted things.
+	DeletedText = "[deleted]"
+)
 
 // TODO: doc -2024-10-22
 type Listing[T any] struct {
 	Data struct {
-		Before   string `json:"before"`
-		After    string `json:"after"`
-		Children []struct {
-			Data T `json:"data"`
-		} `json:"children"`
+		Before   string     `json:"before"`
+		After    string     `json:"after"`
+		Children []Thing[T] `json:"children"`
 	} `json:"data"`
 }
 
+// Thing is an entry in a Listing, with the kind of thing that it is.
+type Thing[T any] struct {
+	Kind Kind `json:"kind"`
+	Data T    `json:"data"`
+}
+
 // TODO: doc -2024-10-22
 func (l *Listing[T]) Items() []T {
 	items := make([]T, 0, len(l.Data.Children))
@@ -30,12 +39,20 @@ func (l *Listing[T]) Items() []T {
 
 // TODO: doc -2024-10-22
 type Comment struct {
-	ID         string     `json:"id"`
-	Body       string     `json:"body"`
-	Permalink  string     `json:"permalink"`
-	Subreddit  string     `json:"subreddit"`
-	Score      int  `json:"score"`
-	CreatedUTC Time `json:"created_utc"`
+	ID         string `json:"id"`
+	Body       string `json:"body"`
+	Permalink  string `json:"permalink"`
+	Subreddit  string `json:"subreddit"`
+	Score      int    `json:"score"`
+	CreatedUTC Time   `json:"created_utc"`
+	// Author is the username of the comment's author, or "[deleted]" once the
+	// comment has been deleted.
+	Author string `json:"author"`
+}
+
+// Fullname returns the comment's fullname, e.g. "t1_abc123".
+func (c Comment) Fullname() string {
+	return commentFullName(c.ID)
 }
 
 // TODO: doc -2024-10-30
@@ -46,6 +63,73 @@ type Post struct {
 	Subreddit  string `json:"subreddit"`
 	Score      int    `json:"score"`
 	CreatedUTC Time   `json:"created_utc"`
+	// Author is the username of the post's author, or "[deleted]" once the
+	// post has been deleted.
+	Author string `json:"author"`
+	// IsSelf is true for self (text) posts, as opposed to link posts. Only
+	// self posts have Selftext, and only they can be edited.
+	IsSelf   bool   `json:"is_self"`
+	Selftext string `json:"selftext"`
+}
+
+// Fullname returns the post's fullname, e.g. "t3_abc123".
+func (p Post) Fullname() string {
+	return postFullName(p.ID)
+}
+
+// Message is a private message, either received or sent by the user.
+type Message struct {
+	ID         string `json:"id"`
+	Author     string `json:"author"`
+	Dest       string `json:"dest"`
+	Subject    string `json:"subject"`
+	Body       string `json:"body"`
+	Subreddit  string `json:"subreddit"`
+	CreatedUTC Time   `json:"created_utc"`
+}
+
+// Fullname returns the message's fullname, e.g. "t4_abc123".
+func (m Message) Fullname() string {
+	return messageFullName(m.ID)
+}
+
+// Permalink returns the path of the message on Reddit. Unlike comments and
+// posts, messages don't include one.
+func (m Message) Permalink() string {
+	return "/message/messages/" + m.ID
+}
+
+// Subreddit is a subreddit that the user is subscribed to.
+type Subreddit struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+	URL         string `json:"url"`
+}
+
+// Fullname returns the subreddit's fullname, e.g. "t5_2qh1i".
+func (s Subreddit) Fullname() string {
+	return subredditFullName(s.ID)
+}
+
+// UserList is a list of Reddit users, such as the authenticated user's friends.
+type UserList struct {
+	Data struct {
+		Children []User `json:"children"`
+	} `json:"data"`
+}
+
+// User is an entry in a UserList. Date is when the relationship (e.g. the
+// friendship) was created.
+type User struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Date Time   `json:"date"`
+}
+
+// Account is the authenticated user's account, as returned by /api/v1/me.
+type Account struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
 }
 
 // Time is a type used to unmarshal Reddit's weird floating point timestamps.
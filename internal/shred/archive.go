@@ -0,0 +1,59 @@
+package shred
+
+import (
+	"fmt"
+
+	"github.com/ccampo133/shreddit-go/internal/archive"
+	"github.com/ccampo133/shreddit-go/internal/reddit"
+)
+
+// archive writes a copy of the given record to the configured archive, if
+// there is one.
+func (s *Shredder) archive(rec archive.Record) error {
+	if s.cfg.Archive == nil {
+		return nil
+	}
+	if err := s.cfg.Archive.Write(rec); err != nil {
+		return fmt.Errorf("error archiving %s: %w", rec.Fullname, err)
+	}
+	return nil
+}
+
+func commentRecord(comment reddit.Comment) archive.Record {
+	return archive.Record{
+		Kind:      archive.KindComment,
+		Fullname:  comment.Fullname(),
+		Subreddit: comment.Subreddit,
+		Body:      comment.Body,
+		Score:     comment.Score,
+		Permalink: comment.Permalink,
+		Created:   comment.CreatedUTC.Time,
+	}
+}
+
+func postRecord(post reddit.Post) archive.Record {
+	return archive.Record{
+		Kind:      archive.KindPost,
+		Fullname:  post.Fullname(),
+		Subreddit: post.Subreddit,
+		Title:     post.Title,
+		Body:      post.Selftext,
+		Score:     post.Score,
+		Permalink: post.Permalink,
+		Created:   post.CreatedUTC.Time,
+	}
+}
+
+// messageRecord returns the record of a private message. Its subject is kept as
+// the title.
+func messageRecord(message reddit.Message) archive.Record {
+	return archive.Record{
+		Kind:      archive.KindMessage,
+		Fullname:  message.Fullname(),
+		Subreddit: message.Subreddit,
+		Title:     message.Subject,
+		Body:      message.Body,
+		Permalink: message.Permalink(),
+		Created:   message.CreatedUTC.Time,
+	}
+}
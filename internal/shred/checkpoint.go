@@ -0,0 +1,263 @@
+package shred
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// Action is something that the Shredder did to a thing.
+type Action string
+
+const (
+	ActionEdited     Action = "edited"
+	ActionDeleted    Action = "deleted"
+	ActionUnsaved    Action = "unsaved"
+	ActionUnfriended Action = "unfriended"
+	ActionUnvoted    Action = "unvoted"
+	ActionUnhidden   Action = "unhidden"
+	// ActionUnsubscribed is keyed by the subreddit's fullname.
+	ActionUnsubscribed Action = "unsubscribed"
+)
+
+// Checkpoint records the progress of a shred run in a local JSON Lines file, so
+// that an interrupted run can be resumed without redoing work that was already
+// done. Each piece of progress is appended to the file as it's recorded, and
+// the file is replayed when it's loaded. It is safe for concurrent use.
+type Checkpoint struct {
+	path string
+	mu   sync.Mutex
+	// done maps things to the last action taken on them. Things are keyed by
+	// their type as well as their fullname, since the same thing can be
+	// shredded by more than one stage (e.g. a post and the user's upvote of
+	// it).
+	done map[thingKey]Action
+	// cursors maps each thing type to the cursor of the next page to process.
+	cursors map[ThingType]checkpointCursor
+	// f is the file that progress is appended to, which is opened when
+	// progress is first recorded.
+	f *os.File
+	// truncate is true if any existing file should be truncated when it's
+	// opened, rather than appended to.
+	truncate bool
+}
+
+// thingKey identifies a thing within a stage of a shred run.
+type thingKey struct {
+	thingType ThingType
+	fullname  string
+}
+
+// checkpointEntry is a line of a checkpoint file, which records either an
+// action taken on a thing, or the cursor of a thing type.
+type checkpointEntry struct {
+	Fullname  string    `json:"fullname,omitempty"`
+	Action    Action    `json:"action,omitempty"`
+	ThingType ThingType `json:"thingType,omitempty"`
+	Cursor    string    `json:"cursor,omitempty"`
+	Source    string    `json:"source,omitempty"`
+}
+
+// checkpointCursor is the cursor of the next page to process for a thing type,
+// along with the source of the things it's a cursor into, since cursors from
+// different sources (e.g. listings and GDPR exports) aren't interchangeable.
+type checkpointCursor struct {
+	source string
+	cursor string
+}
+
+// NewCheckpoint returns an empty checkpoint which is saved to the given path.
+// Any existing file at the path is overwritten the first time that progress is
+// recorded. If the path is empty, the checkpoint is only kept in memory.
+func NewCheckpoint(path string) *Checkpoint {
+	return &Checkpoint{
+		path:     path,
+		done:     make(map[thingKey]Action),
+		cursors:  make(map[ThingType]checkpointCursor),
+		truncate: true,
+	}
+}
+
+// LoadCheckpoint loads the checkpoint saved at the given path, and appends any
+// further progress to it. If there is no file at the path, an empty checkpoint
+// is returned.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	cp := NewCheckpoint(path)
+	cp.truncate = false
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return cp, nil
+		}
+		return nil, fmt.Errorf("error reading checkpoint: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	var pending error
+	// end is the offset of the end of the last line that was read.
+	var end int64
+	for line := 1; scanner.Scan(); line++ {
+		if pending != nil {
+			return nil, pending
+		}
+		start := end
+		end += int64(len(scanner.Bytes())) + 1
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry checkpointEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// The last line may have been cut off if the previous run was
+			// killed while writing it, in which case it's dropped, so that
+			// further progress isn't appended to it.
+			pending = fmt.Errorf("error unmarshalling checkpoint line %d: %w", line, err)
+			end = start
+			continue
+		}
+		cp.apply(entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading checkpoint: %w", err)
+	}
+	if pending != nil {
+		if err := os.Truncate(path, end); err != nil {
+			return nil, fmt.Errorf("error truncating checkpoint: %w", err)
+		}
+	}
+	return cp, nil
+}
+
+// apply updates the in-memory state with an entry. The caller must hold the
+// lock, if the checkpoint is shared.
+func (c *Checkpoint) apply(entry checkpointEntry) {
+	switch {
+	case entry.Fullname != "":
+		c.done[thingKey{thingType: entry.ThingType, fullname: entry.Fullname}] = entry.Action
+	case entry.Cursor == "":
+		delete(c.cursors, entry.ThingType)
+	default:
+		c.cursors[entry.ThingType] = checkpointCursor{source: entry.Source, cursor: entry.Cursor}
+	}
+}
+
+// Len returns the number of things that have had an action recorded.
+func (c *Checkpoint) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.done)
+}
+
+// Action returns the last action recorded for the thing of the given type with
+// the given fullname, or an empty string if there is none.
+func (c *Checkpoint) Action(thingType ThingType, fullname string) Action {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done[thingKey{thingType: thingType, fullname: fullname}]
+}
+
+// Record records that the given action was taken on the thing of the given
+// type with the given fullname, and saves it to the checkpoint file.
+func (c *Checkpoint) Record(thingType ThingType, fullname string, action Action) error {
+	return c.record(checkpointEntry{ThingType: thingType, Fullname: fullname, Action: action})
+}
+
+// Cursor returns the cursor of the next page to process for the given thing
+// type from the given source, or an empty string to start from the beginning.
+// A cursor that was recorded for a different source is ignored.
+func (c *Checkpoint) Cursor(thingType ThingType, source string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cur, ok := c.cursors[thingType]
+	if !ok || cur.source != source {
+		return ""
+	}
+	return cur.cursor
+}
+
+// SetCursor records the cursor of the next page to process for the given
+// thing type from the given source, and saves it to the checkpoint file. An
+// empty cursor clears it.
+func (c *Checkpoint) SetCursor(thingType ThingType, source, cursor string) error {
+	entry := checkpointEntry{ThingType: thingType, Cursor: cursor}
+	if cursor != "" {
+		entry.Source = source
+	}
+	return c.record(entry)
+}
+
+// record applies an entry and appends it to the checkpoint file.
+func (c *Checkpoint) record(entry checkpointEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.apply(entry)
+	if c.path == "" {
+		return nil
+	}
+	if c.f == nil {
+		flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		if c.truncate {
+			flags |= os.O_TRUNC
+		}
+		f, err := os.OpenFile(c.path, flags, 0o600)
+		if err != nil {
+			return fmt.Errorf("error opening checkpoint: %w", err)
+		}
+		c.f = f
+		if !c.truncate {
+			// Start a new line, in case the last one is missing its line
+			// ending. Blank lines are ignored when loading.
+			if _, err := c.f.Write([]byte{'\n'}); err != nil {
+				return fmt.Errorf("error writing checkpoint: %w", err)
+			}
+		}
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error marshalling checkpoint: %w", err)
+	}
+	if _, err := c.f.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("error writing checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Close closes the checkpoint file, leaving it in place so that the run can be
+// resumed.
+func (c *Checkpoint) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.close()
+}
+
+func (c *Checkpoint) close() error {
+	if c.f == nil {
+		return nil
+	}
+	err := c.f.Close()
+	c.f = nil
+	if err != nil {
+		return fmt.Errorf("error closing checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Remove closes and deletes the checkpoint file, e.g. once a run has
+// completed.
+func (c *Checkpoint) Remove() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.path == "" {
+		return nil
+	}
+	if err := c.close(); err != nil {
+		return err
+	}
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error removing checkpoint: %w", err)
+	}
+	return nil
+}
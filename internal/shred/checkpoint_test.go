@@ -0,0 +1,99 @@
+package shred
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	// Loading a checkpoint which doesn't exist yet returns an empty one.
+	cp, err := LoadCheckpoint(path)
+	require.NoError(t, err)
+	require.Zero(t, cp.Len())
+
+	require.NoError(t, cp.Record(ThingTypeComments, "t1_abc", ActionEdited))
+	require.NoError(t, cp.Record(ThingTypeComments, "t1_abc", ActionDeleted))
+	require.NoError(t, cp.Record(ThingTypeSavedPosts, "t3_def", ActionUnsaved))
+	require.NoError(t, cp.SetCursor(ThingTypeComments, "listing", "t1_abc"))
+	require.NoError(t, cp.SetCursor(ThingTypePosts, "listing", "t3_def"))
+	require.NoError(t, cp.SetCursor(ThingTypePosts, "listing", ""))
+
+	// The saved checkpoint has everything that was recorded.
+	cp, err = LoadCheckpoint(path)
+	require.NoError(t, err)
+	require.Equal(t, 2, cp.Len())
+	require.Equal(t, ActionDeleted, cp.Action(ThingTypeComments, "t1_abc"))
+	require.Equal(t, ActionUnsaved, cp.Action(ThingTypeSavedPosts, "t3_def"))
+	require.Empty(t, cp.Action(ThingTypeComments, "t1_xyz"))
+	// Actions are recorded per thing type.
+	require.Empty(t, cp.Action(ThingTypePosts, "t3_def"))
+	require.Equal(t, "t1_abc", cp.Cursor(ThingTypeComments, "listing"))
+	require.Empty(t, cp.Cursor(ThingTypePosts, "listing"))
+	// Cursors from a different source are ignored.
+	require.Empty(t, cp.Cursor(ThingTypeComments, "export"))
+
+	require.NoError(t, cp.Remove())
+	_, err = os.Stat(path)
+	require.ErrorIs(t, err, os.ErrNotExist)
+	// Removing it again is fine.
+	require.NoError(t, cp.Remove())
+}
+
+func TestCheckpoint_InMemory(t *testing.T) {
+	cp := NewCheckpoint("")
+	require.NoError(t, cp.Record(ThingTypeComments, "t1_abc", ActionDeleted))
+	require.Equal(t, ActionDeleted, cp.Action(ThingTypeComments, "t1_abc"))
+	require.NoError(t, cp.Remove())
+}
+
+func TestCheckpoint_AppendsToLoaded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	cp := NewCheckpoint(path)
+	require.NoError(t, cp.Record(ThingTypeComments, "t1_abc", ActionDeleted))
+	require.NoError(t, cp.Close())
+
+	// Progress is appended to a loaded checkpoint, rather than overwriting
+	// it.
+	cp, err := LoadCheckpoint(path)
+	require.NoError(t, err)
+	require.NoError(t, cp.Record(ThingTypeComments, "t1_def", ActionEdited))
+	require.NoError(t, cp.Close())
+	cp, err = LoadCheckpoint(path)
+	require.NoError(t, err)
+	require.Equal(t, 2, cp.Len())
+
+	// A new checkpoint overwrites it.
+	cp = NewCheckpoint(path)
+	require.NoError(t, cp.Record(ThingTypeComments, "t1_xyz", ActionDeleted))
+	require.NoError(t, cp.Close())
+	cp, err = LoadCheckpoint(path)
+	require.NoError(t, err)
+	require.Equal(t, 1, cp.Len())
+}
+
+func TestLoadCheckpoint_TruncatedLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"thingType":"comments","fullname":"t1_abc","action":"deleted"}`+"\n"+`{"fullna`), 0o600))
+	cp, err := LoadCheckpoint(path)
+	require.NoError(t, err)
+	require.Equal(t, ActionDeleted, cp.Action(ThingTypeComments, "t1_abc"))
+
+	// Further progress isn't mangled by the cut off line.
+	require.NoError(t, cp.Record(ThingTypeComments, "t1_def", ActionDeleted))
+	require.NoError(t, cp.Close())
+	cp, err = LoadCheckpoint(path)
+	require.NoError(t, err)
+	require.Equal(t, ActionDeleted, cp.Action(ThingTypeComments, "t1_def"))
+}
+
+func TestLoadCheckpoint_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json\n"+`{"thingType":"comments","fullname":"t1_abc","action":"deleted"}`), 0o600))
+	_, err := LoadCheckpoint(path)
+	require.Error(t, err)
+}
@@ -0,0 +1,42 @@
+package shred
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// RegexFilter keeps items whose text doesn't match any of Patterns.
+type RegexFilter struct {
+	Patterns []*regexp.Regexp
+}
+
+func (f RegexFilter) Filter(item Item) Decision {
+	if pattern := matchContent(f.Patterns, item.Text); pattern != nil {
+		return Decision{Keep: false, Reason: fmt.Sprintf("text matches %q", pattern)}
+	}
+	return Decision{Keep: true, Reason: "text matches no patterns"}
+}
+
+// matchContent returns the first of the patterns which matches the text, or
+// nil if none of them do.
+func matchContent(patterns []*regexp.Regexp, text string) *regexp.Regexp {
+	for _, pattern := range patterns {
+		if pattern.MatchString(text) {
+			return pattern
+		}
+	}
+	return nil
+}
+
+// CompilePatterns compiles each of the given regular expressions.
+func CompilePatterns(exprs []string) ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", expr, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
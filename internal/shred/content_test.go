@@ -0,0 +1,57 @@
+package shred
+
+import (
+	"testing"
+
+	"github.com/ccampo133/shreddit-go/internal/reddit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegexFilter(t *testing.T) {
+	patterns, err := CompilePatterns([]string{`(?i)acme corp`, `widgets`})
+	require.NoError(t, err)
+	f := RegexFilter{Patterns: patterns}
+
+	d := f.Filter(Item{Text: "I work at ACME Corp"})
+	require.Equal(t, Decision{Keep: false, Reason: `text matches "(?i)acme corp"`}, d)
+
+	d = f.Filter(Item{Text: "Something else entirely"})
+	require.Equal(t, Decision{Keep: true, Reason: "text matches no patterns"}, d)
+}
+
+func TestConfig_filters_Content(t *testing.T) {
+	exclude, err := CompilePatterns([]string{`docs\.example\.com`})
+	require.NoError(t, err)
+	include, err := CompilePatterns([]string{`(?i)acme corp`, `widgets`})
+	require.NoError(t, err)
+	cfg := Config{ExcludePatterns: exclude, IncludePatterns: include}
+	f := All(cfg.filters()...)
+
+	d := f.Filter(Item{Type: ThingTypeComments, Text: "I work at ACME Corp"})
+	require.False(t, d.Keep)
+
+	d = f.Filter(Item{Type: ThingTypeComments, Text: "ACME Corp docs are at https://docs.example.com"})
+	require.True(t, d.Keep)
+	require.Equal(t, `type is comments; text matches "docs\\.example\\.com"`, d.Reason)
+
+	d = f.Filter(Item{Type: ThingTypePosts, Text: "Something else entirely"})
+	require.True(t, d.Keep)
+	require.Equal(t, "type is posts; text matches no patterns", d.Reason)
+
+	// Posts are matched on their self text as well as their title.
+	d = f.Filter(postItem(ThingTypePosts, reddit.Post{Title: "Widgets", Selftext: "See docs.example.com"}))
+	require.True(t, d.Keep)
+
+	// Content patterns don't apply to saved things.
+	d = f.Filter(Item{Type: ThingTypeSavedComments, Text: "Something else entirely"})
+	require.False(t, d.Keep)
+
+	// With no patterns, everything can be shredded.
+	cfg = Config{}
+	require.False(t, All(cfg.filters()...).Filter(Item{Text: "anything"}).Keep)
+}
+
+func TestCompilePatterns_Invalid(t *testing.T) {
+	_, err := CompilePatterns([]string{"ok", "(unclosed"})
+	require.ErrorContains(t, err, `invalid pattern "(unclosed"`)
+}
@@ -0,0 +1,322 @@
+package shred
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/ccampo133/shreddit-go/internal/gdpr"
+	"github.com/ccampo133/shreddit-go/internal/reddit"
+)
+
+// exportPageSize is the number of things returned per page by the Discoverer
+// returned by NewExportDiscoverer. It matches the size of Reddit's listings,
+// so that the Shredder paces itself the same way regardless of the source.
+const exportPageSize = 100
+
+// Discoverer finds the things that the Shredder should consider shredding.
+// Each method returns a single page of things, along with a cursor for the
+// next page. An empty cursor means that there are no more pages.
+type Discoverer interface {
+	Comments(ctx context.Context, cursor string) ([]reddit.Comment, string, error)
+	Posts(ctx context.Context, cursor string) ([]reddit.Post, string, error)
+	SavedComments(ctx context.Context, cursor string) ([]reddit.Comment, string, error)
+	SavedPosts(ctx context.Context, cursor string) ([]reddit.Post, string, error)
+}
+
+// NewListingDiscoverer returns a Discoverer which discovers the given user's
+// things using Reddit's listing APIs. Note that Reddit's listings are capped at
+// roughly 1000 things.
+func NewListingDiscoverer(client *reddit.Client, username string) Discoverer {
+	return &listingDiscoverer{client: client, username: username}
+}
+
+type listingDiscoverer struct {
+	client   *reddit.Client
+	username string
+}
+
+func (d *listingDiscoverer) Comments(ctx context.Context, cursor string) ([]reddit.Comment, string, error) {
+	res, err := d.client.GetComments(ctx, d.username, reddit.ListingOptions{After: cursor})
+	if err != nil {
+		return nil, "", err
+	}
+	return res.Items(), res.Data.After, nil
+}
+
+func (d *listingDiscoverer) Posts(ctx context.Context, cursor string) ([]reddit.Post, string, error) {
+	res, err := d.client.GetPosts(ctx, d.username, reddit.ListingOptions{After: cursor})
+	if err != nil {
+		return nil, "", err
+	}
+	return res.Items(), res.Data.After, nil
+}
+
+func (d *listingDiscoverer) SavedComments(ctx context.Context, cursor string) ([]reddit.Comment, string, error) {
+	res, err := d.client.GetSavedComments(ctx, d.username, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	return res.Items(), res.Data.After, nil
+}
+
+func (d *listingDiscoverer) SavedPosts(ctx context.Context, cursor string) ([]reddit.Post, string, error) {
+	res, err := d.client.GetSavedPosts(ctx, d.username, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	return res.Items(), res.Data.After, nil
+}
+
+// sweepListings are the listings requested by the Discoverer returned by
+// NewSweepDiscoverer on each pass. Time windows only apply to the "top" and
+// "controversial" sorts, so "new" and "hot" are only requested once.
+var sweepListings = func() []reddit.ListingOptions {
+	listings := []reddit.ListingOptions{{Sort: reddit.SortNew}, {Sort: reddit.SortHot}}
+	windows := []reddit.TimeWindow{
+		reddit.TimeWindowHour,
+		reddit.TimeWindowDay,
+		reddit.TimeWindowWeek,
+		reddit.TimeWindowMonth,
+		reddit.TimeWindowYear,
+		reddit.TimeWindowAll,
+	}
+	for _, sort := range []reddit.Sort{reddit.SortTop, reddit.SortControversial} {
+		for _, window := range windows {
+			listings = append(listings, reddit.ListingOptions{Sort: sort, Time: window})
+		}
+	}
+	return listings
+}()
+
+// NewSweepDiscoverer returns a Discoverer which discovers the given user's
+// comments and posts by requesting every sort order and time window of Reddit's
+// listings. Each listing is capped at roughly 1000 things, but different sorts
+// return different things, so together they can surface things that the "new"
+// listing alone can't. Things are de-duplicated by fullname, and passes over
+// every listing are repeated until a full pass finds nothing new. Saved things
+// can't be sorted, so they are discovered in the same way as
+// NewListingDiscoverer. Progress between passes is logged to the given logger,
+// or slog's default logger if nil.
+func NewSweepDiscoverer(client *reddit.Client, username string, logger *slog.Logger) Discoverer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &sweepDiscoverer{
+		listingDiscoverer: listingDiscoverer{client: client, username: username},
+		comments:          newSweep(logger),
+		posts:             newSweep(logger),
+	}
+}
+
+type sweepDiscoverer struct {
+	listingDiscoverer
+	comments *sweep
+	posts    *sweep
+}
+
+func (d *sweepDiscoverer) Comments(ctx context.Context, cursor string) ([]reddit.Comment, string, error) {
+	list := func(opts reddit.ListingOptions) (*reddit.Listing[reddit.Comment], error) {
+		return d.client.GetComments(ctx, d.username, opts)
+	}
+	return sweepPage(d.comments, cursor, reddit.Comment.Fullname, list)
+}
+
+func (d *sweepDiscoverer) Posts(ctx context.Context, cursor string) ([]reddit.Post, string, error) {
+	list := func(opts reddit.ListingOptions) (*reddit.Listing[reddit.Post], error) {
+		return d.client.GetPosts(ctx, d.username, opts)
+	}
+	return sweepPage(d.posts, cursor, reddit.Post.Fullname, list)
+}
+
+// sweep tracks the progress of sweeping over a single type of thing.
+type sweep struct {
+	// seen contains the fullnames of all things returned so far.
+	seen map[string]struct{}
+	// found is the number of new things found during the current pass.
+	found int
+	// pass is the current pass number, starting at 1.
+	pass   int
+	logger *slog.Logger
+}
+
+func newSweep(logger *slog.Logger) *sweep {
+	return &sweep{seen: make(map[string]struct{}), logger: logger}
+}
+
+// sweepPage requests a single page of one of the sweepListings and returns the
+// things on it that haven't been seen yet. The cursor has the form
+// "{listing index}:{after}", where the listing index is an index into
+// sweepListings. An empty cursor is the start of a pass.
+func sweepPage[T any](
+	sw *sweep,
+	cursor string,
+	fullname func(T) string,
+	list func(reddit.ListingOptions) (*reddit.Listing[T], error),
+) ([]T, string, error) {
+	idx, after, err := parseSweepCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if idx == 0 && after == "" {
+		sw.pass++
+		sw.found = 0
+	}
+	opts := sweepListings[idx]
+	opts.After = after
+	res, err := list(opts)
+	if err != nil {
+		return nil, "", err
+	}
+	var items []T
+	for _, item := range res.Items() {
+		name := fullname(item)
+		if _, ok := sw.seen[name]; ok {
+			continue
+		}
+		sw.seen[name] = struct{}{}
+		sw.found++
+		items = append(items, item)
+	}
+	switch {
+	case res.Data.After != "":
+		// More pages of the current listing.
+		return items, fmt.Sprintf("%d:%s", idx, res.Data.After), nil
+	case idx < len(sweepListings)-1:
+		// Move on to the next listing.
+		return items, fmt.Sprintf("%d:", idx+1), nil
+	case sw.found > 0:
+		// End of the pass, but it found new things, which may have uncovered
+		// more - sweep again.
+		sw.logger.Info("Sweep pass found new things; sweeping again", "pass", sw.pass, "found", sw.found)
+		return items, fmt.Sprintf("%d:", 0), nil
+	default:
+		// End of a pass which found nothing new. We're done.
+		return items, "", nil
+	}
+}
+
+func parseSweepCursor(cursor string) (int, string, error) {
+	if cursor == "" {
+		return 0, "", nil
+	}
+	idxStr, after, ok := strings.Cut(cursor, ":")
+	if !ok {
+		return 0, "", fmt.Errorf("invalid cursor %q", cursor)
+	}
+	idx, err := strconv.Atoi(idxStr)
+	if err != nil || idx < 0 || idx >= len(sweepListings) {
+		return 0, "", fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return idx, after, nil
+}
+
+// NewExportDiscoverer returns a Discoverer which discovers things from a GDPR
+// data export rather than Reddit's APIs. Unlike Reddit's listings, the export
+// contains the user's entire history. The export lacks scores and other
+// details, and includes things which have since been deleted, so each page is
+// looked up with the client to get the things as they currently are. Things
+// which no longer exist are left out, as are the user's own comments and posts
+// which are already deleted.
+func NewExportDiscoverer(client *reddit.Client, export *gdpr.Export) Discoverer {
+	return &exportDiscoverer{client: client, export: export}
+}
+
+type exportDiscoverer struct {
+	client *reddit.Client
+	export *gdpr.Export
+}
+
+func (d *exportDiscoverer) Comments(ctx context.Context, cursor string) ([]reddit.Comment, string, error) {
+	return lookupPage(ctx, d.client, d.export.Comments, cursor, commentFullname, commentsOf, notDeletedComment)
+}
+
+func (d *exportDiscoverer) Posts(ctx context.Context, cursor string) ([]reddit.Post, string, error) {
+	return lookupPage(ctx, d.client, d.export.Posts, cursor, postFullname, postsOf, notDeletedPost)
+}
+
+func (d *exportDiscoverer) SavedComments(ctx context.Context, cursor string) ([]reddit.Comment, string, error) {
+	// Deleted things can still be saved, so they're kept here.
+	return lookupPage(ctx, d.client, d.export.SavedComments, cursor, commentFullname, commentsOf, nil)
+}
+
+func (d *exportDiscoverer) SavedPosts(ctx context.Context, cursor string) ([]reddit.Post, string, error) {
+	return lookupPage(ctx, d.client, d.export.SavedPosts, cursor, postFullname, postsOf, nil)
+}
+
+func commentFullname(comment reddit.Comment) reddit.Fullname {
+	return reddit.Fullname{Kind: reddit.KindComment, ID: comment.ID}
+}
+
+func postFullname(post reddit.Post) reddit.Fullname {
+	return reddit.Fullname{Kind: reddit.KindPost, ID: post.ID}
+}
+
+func commentsOf(things *reddit.Things) []reddit.Comment { return things.Comments }
+
+func postsOf(things *reddit.Things) []reddit.Post { return things.Posts }
+
+func notDeletedComment(comment reddit.Comment) bool { return comment.Author != reddit.DeletedText }
+
+func notDeletedPost(post reddit.Post) bool { return post.Author != reddit.DeletedText }
+
+// lookupPage returns a page of items like page, but replaced with their
+// current versions from Reddit, in the same order. Items which Reddit doesn't
+// return, or for which keep (if non-nil) returns false, are left out, so pages
+// may be smaller than exportPageSize, or even empty.
+func lookupPage[T any](
+	ctx context.Context,
+	client *reddit.Client,
+	items []T,
+	cursor string,
+	fullname func(T) reddit.Fullname,
+	found func(*reddit.Things) []T,
+	keep func(T) bool,
+) ([]T, string, error) {
+	items, next, err := page(items, cursor)
+	if err != nil || len(items) == 0 {
+		return nil, next, err
+	}
+	fullnames := make([]reddit.Fullname, 0, len(items))
+	for _, item := range items {
+		fullnames = append(fullnames, fullname(item))
+	}
+	things, err := client.GetThingsByFullname(ctx, fullnames)
+	if err != nil {
+		return nil, "", fmt.Errorf("error looking up things from the export: %w", err)
+	}
+	current := make(map[reddit.Fullname]T, len(fullnames))
+	for _, item := range found(things) {
+		current[fullname(item)] = item
+	}
+	var res []T
+	for _, name := range fullnames {
+		if item, ok := current[name]; ok && (keep == nil || keep(item)) {
+			res = append(res, item)
+		}
+	}
+	return res, next, nil
+}
+
+// page returns a page of at most exportPageSize items, starting at the offset
+// given by the cursor. The returned cursor is the offset of the next page.
+func page[T any](items []T, cursor string) ([]T, string, error) {
+	start := 0
+	if cursor != "" {
+		var err error
+		if start, err = strconv.Atoi(cursor); err != nil || start < 0 {
+			return nil, "", fmt.Errorf("invalid cursor %q", cursor)
+		}
+	}
+	if start >= len(items) {
+		return nil, "", nil
+	}
+	end := min(start+exportPageSize, len(items))
+	next := ""
+	if end < len(items) {
+		next = strconv.Itoa(end)
+	}
+	return items[start:end], next, nil
+}
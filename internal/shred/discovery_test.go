@@ -0,0 +1,167 @@
+package shred
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ccampo133/shreddit-go/internal/gdpr"
+	"github.com/ccampo133/shreddit-go/internal/reddit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportDiscoverer_Comments(t *testing.T) {
+	comments := make([]reddit.Comment, exportPageSize+2)
+	for i := range comments {
+		comments[i].ID = strconv.Itoa(i)
+	}
+	var lookups [][]string
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/info", r.URL.Path)
+			fullnames := strings.Split(r.URL.Query().Get("id"), ",")
+			lookups = append(lookups, fullnames)
+			// Every comment exists with a score, except that "100" is
+			// already deleted and "101" is gone entirely. They're returned
+			// in reverse to check that the export's order is kept.
+			var children []string
+			for _, fullname := range slices.Backward(fullnames) {
+				id := strings.TrimPrefix(fullname, "t1_")
+				author := "test_username"
+				switch id {
+				case "100":
+					author = reddit.DeletedText
+				case "101":
+					continue
+				}
+				children = append(
+					children,
+					fmt.Sprintf(`{"kind": "t1", "data": {"id": %q, "author": %q, "score": 42}}`, id, author),
+				)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"data": {"children": [%s]}}`, strings.Join(children, ","))
+		},
+	)
+	d := NewExportDiscoverer(client, &gdpr.Export{Comments: comments})
+
+	page1, cursor, err := d.Comments(context.Background(), "")
+	require.NoError(t, err)
+	require.Len(t, page1, exportPageSize)
+	require.Equal(t, "100", cursor)
+	require.Equal(t, "0", page1[0].ID)
+	require.Equal(t, "99", page1[exportPageSize-1].ID)
+	require.Equal(t, 42, page1[0].Score)
+
+	page2, cursor, err := d.Comments(context.Background(), cursor)
+	require.NoError(t, err)
+	require.Empty(t, page2)
+	require.Empty(t, cursor)
+	require.Equal(t, []string{"t1_100", "t1_101"}, lookups[1])
+
+	_, _, err = d.Comments(context.Background(), "nope")
+	require.Error(t, err)
+}
+
+func TestExportDiscoverer_SavedPosts_KeepsDeleted(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"data": {"children": [{"kind": "t3", "data": {"id": "a", "author": "[deleted]"}}]}}`))
+		},
+	)
+	d := NewExportDiscoverer(client, &gdpr.Export{SavedPosts: []reddit.Post{{ID: "a"}, {ID: "b"}}})
+	posts, cursor, err := d.SavedPosts(context.Background(), "")
+	require.NoError(t, err)
+	require.Equal(t, []reddit.Post{{ID: "a", Author: reddit.DeletedText}}, posts)
+	require.Empty(t, cursor)
+}
+
+func TestExportDiscoverer_Empty(t *testing.T) {
+	client := newTestClient(
+		t, func(_ http.ResponseWriter, _ *http.Request) {
+			t.Fatal("unexpected request")
+		},
+	)
+	d := NewExportDiscoverer(client, &gdpr.Export{})
+	posts, cursor, err := d.Posts(context.Background(), "")
+	require.NoError(t, err)
+	require.Empty(t, posts)
+	require.Empty(t, cursor)
+}
+
+func TestSweepPage(t *testing.T) {
+	// Each listing returns the same two comments, except for the very first
+	// request, which only returns the first one and has a second page.
+	var requests []reddit.ListingOptions
+	list := func(opts reddit.ListingOptions) (*reddit.Listing[reddit.Comment], error) {
+		requests = append(requests, opts)
+		var l reddit.Listing[reddit.Comment]
+		if len(requests) == 1 {
+			l.Data.After = "t1_a"
+			l.Data.Children = append(l.Data.Children, listingChild(reddit.Comment{ID: "a"}))
+			return &l, nil
+		}
+		l.Data.Children = append(
+			l.Data.Children,
+			listingChild(reddit.Comment{ID: "a"}),
+			listingChild(reddit.Comment{ID: "b"}),
+		)
+		return &l, nil
+	}
+
+	var logs bytes.Buffer
+	sw := newSweep(slog.New(slog.NewTextHandler(&logs, nil)).With("profile", "alt"))
+	var found []string
+	cursor := ""
+	for {
+		items, next, err := sweepPage(sw, cursor, reddit.Comment.Fullname, list)
+		require.NoError(t, err)
+		for _, item := range items {
+			found = append(found, item.ID)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	// Each thing is only returned once.
+	require.Equal(t, []string{"a", "b"}, found)
+	// Two full passes: the first found new things, the second didn't. The
+	// first listing was requested twice in the first pass.
+	require.Equal(t, 2, sw.pass)
+	require.Len(t, requests, 2*len(sweepListings)+1)
+	require.Equal(t, reddit.ListingOptions{Sort: reddit.SortNew, After: "t1_a"}, requests[1])
+	require.Equal(t, reddit.ListingOptions{Sort: reddit.SortControversial, Time: reddit.TimeWindowAll}, requests[len(requests)-1])
+	// Progress is logged with the given logger.
+	require.Contains(t, logs.String(), "sweeping again")
+	require.Contains(t, logs.String(), "profile=alt")
+}
+
+func TestParseSweepCursor(t *testing.T) {
+	idx, after, err := parseSweepCursor("")
+	require.NoError(t, err)
+	require.Equal(t, 0, idx)
+	require.Empty(t, after)
+
+	idx, after, err = parseSweepCursor("3:t1_abc")
+	require.NoError(t, err)
+	require.Equal(t, 3, idx)
+	require.Equal(t, "t1_abc", after)
+
+	_, _, err = parseSweepCursor("1000:")
+	require.Error(t, err)
+	_, _, err = parseSweepCursor("t1_abc")
+	require.Error(t, err)
+}
+
+func listingChild[T any](data T) reddit.Thing[T] {
+	return reddit.Thing[T]{Data: data}
+}
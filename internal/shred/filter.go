@@ -0,0 +1,233 @@
+package shred
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ccampo133/shreddit-go/internal/reddit"
+)
+
+// Item is a normalized view of a thing which may be shredded, so that the same
+// filters can be applied to every type of thing.
+type Item struct {
+	Type      ThingType
+	Fullname  string
+	Subreddit string
+	// Text is the text content of the thing, e.g. a comment's body or a post's
+	// title and self text.
+	Text      string
+	Score     int
+	Created   time.Time
+	Permalink string
+}
+
+// commentItem returns the Item for a comment of the given thing type.
+func commentItem(thingType ThingType, comment reddit.Comment) Item {
+	return Item{
+		Type:      thingType,
+		Fullname:  comment.Fullname(),
+		Subreddit: comment.Subreddit,
+		Text:      comment.Body,
+		Score:     comment.Score,
+		Created:   comment.CreatedUTC.Time,
+		Permalink: comment.Permalink,
+	}
+}
+
+// postItem returns the Item for a post of the given thing type.
+func postItem(thingType ThingType, post reddit.Post) Item {
+	text := post.Title
+	if post.Selftext != "" {
+		text += "\n" + post.Selftext
+	}
+	return Item{
+		Type:      thingType,
+		Fullname:  post.Fullname(),
+		Subreddit: post.Subreddit,
+		Text:      text,
+		Score:     post.Score,
+		Created:   post.CreatedUTC.Time,
+		Permalink: post.Permalink,
+	}
+}
+
+// messageItem returns the Item for a private message. Messages have no score.
+func messageItem(message reddit.Message) Item {
+	return Item{
+		Type:      ThingTypeMessages,
+		Fullname:  message.Fullname(),
+		Subreddit: message.Subreddit,
+		Text:      message.Body,
+		Created:   message.CreatedUTC.Time,
+		Permalink: message.Permalink(),
+	}
+}
+
+// subredditItem returns the Item for a subreddit that the user is subscribed
+// to. Reddit doesn't say when the user subscribed, so it has no creation time,
+// and age filters never keep it.
+func subredditItem(subreddit reddit.Subreddit) Item {
+	return Item{
+		Type:      ThingTypeSubscriptions,
+		Fullname:  subreddit.Fullname(),
+		Subreddit: subreddit.DisplayName,
+		Permalink: subreddit.URL,
+	}
+}
+
+// friendItem returns the Item for one of the user's friends, which was created
+// when they became friends.
+func friendItem(friend reddit.User) Item {
+	return Item{
+		Type:      ThingTypeFriends,
+		Fullname:  friend.ID,
+		Created:   friend.Date.Time,
+		Permalink: "/user/" + friend.Name,
+	}
+}
+
+// Decision is the result of applying a Filter to an Item.
+type Decision struct {
+	// Keep is true if the item should be kept, and false if it may be
+	// shredded.
+	Keep bool
+	// Reason describes what the filter found, e.g. "score 10 > max score 5".
+	// Reasons are phrased so that they make sense whether or not the decision
+	// is negated by Not.
+	Reason string
+}
+
+// Filter decides whether an item should be kept or may be shredded.
+type Filter interface {
+	Filter(item Item) Decision
+}
+
+// FilterFunc adapts an ordinary function to a Filter.
+type FilterFunc func(item Item) Decision
+
+func (f FilterFunc) Filter(item Item) Decision {
+	return f(item)
+}
+
+// All returns a Filter which keeps an item if any of the given filters keeps
+// it, i.e. an item may only be shredded if all the filters agree. The reason
+// for keeping an item is that of the first filter to keep it.
+func All(filters ...Filter) Filter {
+	return FilterFunc(
+		func(item Item) Decision {
+			var reasons []string
+			for _, f := range filters {
+				d := f.Filter(item)
+				if d.Keep {
+					return d
+				}
+				reasons = appendReason(reasons, d.Reason)
+			}
+			return Decision{Keep: false, Reason: strings.Join(reasons, "; ")}
+		},
+	)
+}
+
+// Any returns a Filter which allows an item to be shredded if any of the given
+// filters allows it, i.e. an item is only kept if all the filters agree. The
+// reason for shredding an item is that of the first filter to allow it.
+func Any(filters ...Filter) Filter {
+	return FilterFunc(
+		func(item Item) Decision {
+			var reasons []string
+			for _, f := range filters {
+				d := f.Filter(item)
+				if !d.Keep {
+					return d
+				}
+				reasons = appendReason(reasons, d.Reason)
+			}
+			return Decision{Keep: true, Reason: strings.Join(reasons, "; ")}
+		},
+	)
+}
+
+// Not returns a Filter which inverts the decisions of the given filter.
+func Not(filter Filter) Filter {
+	return FilterFunc(
+		func(item Item) Decision {
+			d := filter.Filter(item)
+			d.Keep = !d.Keep
+			return d
+		},
+	)
+}
+
+func appendReason(reasons []string, reason string) []string {
+	if reason == "" {
+		return reasons
+	}
+	return append(reasons, reason)
+}
+
+// AgeFilter keeps items created after Before. Items with no creation time
+// (e.g. saved things from a GDPR export) may always be shredded.
+type AgeFilter struct {
+	Before time.Time
+}
+
+func (f AgeFilter) Filter(item Item) Decision {
+	if item.Created.After(f.Before) {
+		return Decision{Keep: true, Reason: "created after cutoff"}
+	}
+	return Decision{Keep: false, Reason: "created before cutoff"}
+}
+
+// ScoreFilter keeps items with a score greater than Max.
+type ScoreFilter struct {
+	Max int
+}
+
+func (f ScoreFilter) Filter(item Item) Decision {
+	if item.Score > f.Max {
+		return Decision{Keep: true, Reason: fmt.Sprintf("score %d > max score %d", item.Score, f.Max)}
+	}
+	return Decision{Keep: false, Reason: fmt.Sprintf("score %d <= max score %d", item.Score, f.Max)}
+}
+
+// TypeFilter keeps items which aren't one of Types.
+type TypeFilter struct {
+	Types []ThingType
+}
+
+func (f TypeFilter) Filter(item Item) Decision {
+	if slices.Contains(f.Types, item.Type) {
+		return Decision{Keep: false, Reason: fmt.Sprintf("type is %s", item.Type)}
+	}
+	return Decision{Keep: true, Reason: fmt.Sprintf("type %s isn't one of %s", item.Type, JoinThingTypes(f.Types))}
+}
+
+// filters returns the chain of filters configured by cfg: the built-in
+// filters for the options which are set, followed by cfg.Filters.
+func (cfg *Config) filters() []Filter {
+	filters := []Filter{AgeFilter{Before: cfg.Before}}
+	if cfg.MaxScore != nil {
+		filters = append(filters, ScoreFilter{Max: *cfg.MaxScore})
+	}
+	// Private messages and friends aren't in any subreddit, so they pass
+	// straight through the subreddit filters.
+	noSubreddit := Not(TypeFilter{Types: SubredditThingTypes})
+	if len(cfg.KeepSubreddits) > 0 {
+		filters = append(filters, Any(noSubreddit, Not(SubredditFilter{Patterns: cfg.KeepSubreddits})))
+	}
+	if len(cfg.OnlySubreddits) > 0 {
+		filters = append(filters, Any(noSubreddit, SubredditFilter{Patterns: cfg.OnlySubreddits}))
+	}
+	// Content patterns only apply to the user's own comments and posts, so
+	// anything else passes straight through them.
+	notOwn := Not(TypeFilter{Types: []ThingType{ThingTypeComments, ThingTypePosts}})
+	if len(cfg.ExcludePatterns) > 0 {
+		filters = append(filters, Any(notOwn, Not(RegexFilter{Patterns: cfg.ExcludePatterns})))
+	}
+	if len(cfg.IncludePatterns) > 0 {
+		filters = append(filters, Any(notOwn, RegexFilter{Patterns: cfg.IncludePatterns}))
+	}
+	return append(filters, cfg.Filters...)
+}
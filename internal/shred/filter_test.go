@@ -0,0 +1,91 @@
+package shred
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	keepFilter  = FilterFunc(func(Item) Decision { return Decision{Keep: true, Reason: "keep"} })
+	shredFilter = FilterFunc(func(Item) Decision { return Decision{Keep: false, Reason: "shred"} })
+)
+
+func TestAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []Filter
+		want    Decision
+	}{
+		{name: "empty", want: Decision{Keep: false}},
+		{name: "all shred", filters: []Filter{shredFilter, shredFilter}, want: Decision{Keep: false, Reason: "shred; shred"}},
+		{name: "one keeps", filters: []Filter{shredFilter, keepFilter}, want: Decision{Keep: true, Reason: "keep"}},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				require.Equal(t, tt.want, All(tt.filters...).Filter(Item{}))
+			},
+		)
+	}
+}
+
+func TestAny(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []Filter
+		want    Decision
+	}{
+		{name: "empty", want: Decision{Keep: true}},
+		{name: "all keep", filters: []Filter{keepFilter, keepFilter}, want: Decision{Keep: true, Reason: "keep; keep"}},
+		{name: "one shreds", filters: []Filter{keepFilter, shredFilter}, want: Decision{Keep: false, Reason: "shred"}},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				require.Equal(t, tt.want, Any(tt.filters...).Filter(Item{}))
+			},
+		)
+	}
+}
+
+func TestNot(t *testing.T) {
+	require.Equal(t, Decision{Keep: false, Reason: "keep"}, Not(keepFilter).Filter(Item{}))
+	require.Equal(t, Decision{Keep: true, Reason: "shred"}, Not(shredFilter).Filter(Item{}))
+}
+
+func TestAgeFilter(t *testing.T) {
+	cutoff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := AgeFilter{Before: cutoff}
+	require.True(t, f.Filter(Item{Created: cutoff.Add(time.Second)}).Keep)
+	require.False(t, f.Filter(Item{Created: cutoff.Add(-time.Second)}).Keep)
+	// Things with no creation time can always be shredded.
+	require.False(t, f.Filter(Item{}).Keep)
+}
+
+func TestScoreFilter(t *testing.T) {
+	f := ScoreFilter{Max: 5}
+	require.Equal(t, Decision{Keep: true, Reason: "score 10 > max score 5"}, f.Filter(Item{Score: 10}))
+	require.Equal(t, Decision{Keep: false, Reason: "score 5 <= max score 5"}, f.Filter(Item{Score: 5}))
+}
+
+func TestTypeFilter(t *testing.T) {
+	f := TypeFilter{Types: []ThingType{ThingTypeComments, ThingTypePosts}}
+	require.Equal(t, Decision{Keep: false, Reason: "type is comments"}, f.Filter(Item{Type: ThingTypeComments}))
+	require.Equal(
+		t,
+		Decision{Keep: true, Reason: "type saved-posts isn't one of comments,posts"},
+		f.Filter(Item{Type: ThingTypeSavedPosts}),
+	)
+}
+
+func TestConfig_filters_Custom(t *testing.T) {
+	// Custom filters are evaluated after the built-in ones.
+	cfg := Config{Before: time.Now(), Filters: []Filter{keepFilter}}
+	d := All(cfg.filters()...).Filter(Item{})
+	require.Equal(t, Decision{Keep: true, Reason: "keep"}, d)
+
+	d = All(cfg.filters()...).Filter(Item{Created: time.Now().Add(time.Hour)})
+	require.Equal(t, Decision{Keep: true, Reason: "created after cutoff"}, d)
+}
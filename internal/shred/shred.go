@@ -1,10 +1,14 @@
 package shred
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/ccampo133/shreddit-go/internal/archive"
 	"github.com/ccampo133/shreddit-go/internal/reddit"
 )
 
@@ -21,18 +25,79 @@ type Config struct {
 	SkipPosts          bool
 	SkipSavedComments  bool
 	SkipSavedPosts     bool
+	SkipFriends        bool
 	EditOnly           bool
 	Before             time.Time
 	MaxScore           *int
 	MaxDays            *int
 	ReplacementComment string
-	Sleep              time.Duration
+	// Sleep is an optional extra delay between things and pages. It isn't
+	// needed to avoid rate limiting, since reddit.Client paces its requests
+	// according to Reddit's rate limit headers.
+	Sleep time.Duration
+	// KeepSubreddits are glob patterns of subreddits whose things are never
+	// shredded. Matching is case-insensitive.
+	KeepSubreddits []string
+	// OnlySubreddits, if non-empty, are glob patterns of the only subreddits
+	// whose things are shredded. KeepSubreddits takes precedence. Neither
+	// applies to private messages or friends, which aren't in a subreddit.
+	OnlySubreddits []string
+	// ExcludePatterns are regular expressions matched against the bodies of
+	// comments and the titles and self text of posts. Things that match any of
+	// them are never shredded.
+	ExcludePatterns []*regexp.Regexp
+	// IncludePatterns, if non-empty, are regular expressions matched in the
+	// same way as ExcludePatterns. Only things that match at least one of them
+	// are shredded. ExcludePatterns takes precedence.
+	IncludePatterns []*regexp.Regexp
+	// Filters are evaluated after the built-in filters configured by the
+	// options above. A thing is only shredded if every filter allows it, so
+	// these can only narrow down what is shredded; use Any and Not to build
+	// more elaborate rules.
+	Filters []Filter
+	// Discoverer finds the comments and posts to shred. If nil, Reddit's
+	// listing APIs are used.
+	Discoverer Discoverer
+	// Checkpoint records progress so that an interrupted run can be resumed.
+	// Things which it records as already shredded are skipped. If nil,
+	// progress is only tracked in memory.
+	Checkpoint *Checkpoint
+	// Archive, if set, receives a copy of every comment and post before it is
+	// shredded, including during a dry run.
+	Archive archive.Writer
+	// Logger is used to log progress. If nil, slog's default logger is used.
+	Logger *slog.Logger
+	// Verify re-fetches comments and posts after each page has been shredded
+	// to check that the edits and deletions took effect, retrying them once
+	// if not.
+	Verify bool
+	// SkipUpvoted and SkipDownvoted skip removing the user's votes on posts.
+	SkipUpvoted   bool
+	SkipDownvoted bool
+	// SkipHidden skips unhiding the posts that the user has hidden.
+	SkipHidden bool
+	// SkipMessages skips deleting the user's received and sent private
+	// messages.
+	SkipMessages bool
+	// SkipSubscriptions skips unsubscribing from subreddits. Use
+	// KeepSubreddits to stay subscribed to some of them.
+	SkipSubscriptions bool
+}
+
+// Validate checks the config for invalid values.
+func (cfg *Config) Validate() error {
+	if err := ValidateSubredditPatterns(cfg.KeepSubreddits); err != nil {
+		return err
+	}
+	return ValidateSubredditPatterns(cfg.OnlySubreddits)
 }
 
 // TODO: doc -2024-10-30
 type Shredder struct {
-	client *reddit.Client
-	cfg    Config
+	client  *reddit.Client
+	cfg     Config
+	filter  Filter
+	summary Summary
 }
 
 // TODO: doc -2024-10-30
@@ -46,158 +111,668 @@ func NewShredder(client *reddit.Client, cfg Config) *Shredder {
 	if cfg.ReplacementComment == "" {
 		cfg.ReplacementComment = DefaultReplacementComment
 	}
-	if cfg.Sleep == 0 {
-		cfg.Sleep = 2 * time.Second
+	if cfg.Discoverer == nil {
+		cfg.Discoverer = NewListingDiscoverer(client, cfg.Username)
 	}
-	return &Shredder{client: client, cfg: cfg}
+	if cfg.Checkpoint == nil {
+		cfg.Checkpoint = NewCheckpoint("")
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return &Shredder{client: client, cfg: cfg, filter: All(cfg.filters()...), summary: newSummary()}
 }
 
-// TODO: doc -2024-10-30
-func (s *Shredder) Shred() error {
+// Shred shreds each type of thing which isn't skipped by the config, in turn.
+// If ctx is cancelled, Shred stops once it has finished with the thing that it
+// is currently shredding, and returns the context's error. Either way,
+// Summary reports what was done.
+func (s *Shredder) Shred(ctx context.Context) error {
 	// Comments
 	if !s.cfg.SkipComments {
-		if err := s.pager(s.shredComments); err != nil {
+		if err := s.pager(ctx, ThingTypeComments, s.shredComments); err != nil {
 			return fmt.Errorf("error shredding comments: %w", err)
 		}
 	}
 	// Posts
 	if !s.cfg.SkipPosts {
-		if err := s.pager(s.shredPosts); err != nil {
+		if err := s.pager(ctx, ThingTypePosts, s.shredPosts); err != nil {
 			return fmt.Errorf("error shredding posts: %w", err)
 		}
 	}
 	// Saved comments
 	if !s.cfg.SkipSavedComments {
-		if err := s.pager(s.shredSavedComments); err != nil {
+		if err := s.pager(ctx, ThingTypeSavedComments, s.shredSavedComments); err != nil {
 			return fmt.Errorf("error shredding saved comments: %w", err)
 		}
 	}
 	// Saved posts
 	if !s.cfg.SkipSavedPosts {
-		if err := s.pager(s.shredSavedPosts); err != nil {
+		if err := s.pager(ctx, ThingTypeSavedPosts, s.shredSavedPosts); err != nil {
 			return fmt.Errorf("error shredding saved posts: %w", err)
 		}
 	}
+	// Upvotes
+	if !s.cfg.SkipUpvoted {
+		if err := s.pager(ctx, ThingTypeUpvoted, s.shredVotes(ThingTypeUpvoted)); err != nil {
+			return fmt.Errorf("error shredding upvotes: %w", err)
+		}
+	}
+	// Downvotes
+	if !s.cfg.SkipDownvoted {
+		if err := s.pager(ctx, ThingTypeDownvoted, s.shredVotes(ThingTypeDownvoted)); err != nil {
+			return fmt.Errorf("error shredding downvotes: %w", err)
+		}
+	}
+	// Hidden posts
+	if !s.cfg.SkipHidden {
+		if err := s.pager(ctx, ThingTypeHidden, s.shredHidden); err != nil {
+			return fmt.Errorf("error shredding hidden posts: %w", err)
+		}
+	}
+	// Messages
+	if !s.cfg.SkipMessages {
+		if err := s.pager(ctx, ThingTypeMessages, s.shredMessages); err != nil {
+			return fmt.Errorf("error shredding messages: %w", err)
+		}
+	}
+	// Subscriptions
+	if !s.cfg.SkipSubscriptions {
+		if err := s.pager(ctx, ThingTypeSubscriptions, s.shredSubscriptions); err != nil {
+			return fmt.Errorf("error shredding subscriptions: %w", err)
+		}
+	}
+	// Friends
+	if !s.cfg.SkipFriends {
+		if err := s.pager(ctx, ThingTypeFriends, s.shredFriends); err != nil {
+			return fmt.Errorf("error shredding friends: %w", err)
+		}
+	}
 	return nil
 }
 
 // TODO: doc -2024-10-30
-func (s *Shredder) shredComments(after string) (string, error) {
-	res, err := s.client.GetComments(s.cfg.Username, after)
+func (s *Shredder) shredComments(ctx context.Context, after string) (string, error) {
+	comments, next, err := s.cfg.Discoverer.Comments(ctx, after)
 	if err != nil {
 		return "", fmt.Errorf("error getting comments: %w", err)
 	}
-	for i, comment := range res.Data.Children {
-		// Skip comments younger than the cutoff time.
-		if comment.Data.CreatedUTC.After(s.cfg.Before) {
-			slog.Info(
-				"Skipping comment (created after cutoff)",
-				"created", comment.Data.CreatedUTC.Time,
-				"permalink", comment.Data.Permalink,
-			)
+	// Once started, each thing is shredded to completion even if the run is
+	// cancelled, so that nothing is left half-shredded (e.g. edited but not
+	// deleted). Cancellation is checked between things instead.
+	opCtx := context.WithoutCancel(ctx)
+	var shredded []reddit.Comment
+	for i, comment := range comments {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		// Skip comments already shredded by a previous run.
+		action := s.cfg.Checkpoint.Action(ThingTypeComments, comment.Fullname())
+		if action == ActionDeleted || (s.cfg.EditOnly && action == ActionEdited) {
+			s.cfg.Logger.Info("Skipping comment (already shredded)", "permalink", comment.Permalink)
+			s.summary.skip(ThingTypeComments)
 			continue
 		}
-		// Skip comments with a score above the cutoff.
-		if s.cfg.MaxScore != nil && comment.Data.Score > *s.cfg.MaxScore {
-			slog.Info(
-				"Skipping comment (score > max score)",
-				"score", comment.Data.Score,
-				"permalink", comment.Data.Permalink,
-			)
+		// Skip comments which the filters keep.
+		if d := s.filter.Filter(commentItem(ThingTypeComments, comment)); d.Keep {
+			s.cfg.Logger.Info("Skipping comment", "reason", d.Reason, "permalink", comment.Permalink)
+			s.summary.skip(ThingTypeComments)
 			continue
 		}
+		// Archive the comment before it's destroyed. If a previous run already
+		// edited it, the original is gone (and was archived by that run).
+		if action != ActionEdited {
+			if err := s.archive(commentRecord(comment)); err != nil {
+				return "", err
+			}
+		}
 		// Dry run; just log what we would do.
 		if s.cfg.DryRun {
-			slog.Info("Would shred comment (dry-run)", "permalink", comment.Data.Permalink)
+			s.cfg.Logger.Info("Would shred comment (dry-run)", "permalink", comment.Permalink)
+			s.summary.shred(ThingTypeComments)
 			continue
 		}
-		// Edit the comment.
-		if err := s.client.EditComment(comment.Data.ID, s.cfg.ReplacementComment); err != nil {
-			// TODO: handle rate limiting error -2024-10-31
-			return "", fmt.Errorf("error editing comment: %w", err)
+		// Edit the comment, unless a previous run already did.
+		if action != ActionEdited {
+			if err := s.client.EditComment(opCtx, comment.ID, s.cfg.ReplacementComment); err != nil {
+				return "", fmt.Errorf("error editing comment: %w", err)
+			}
+			if err := s.cfg.Checkpoint.Record(ThingTypeComments, comment.Fullname(), ActionEdited); err != nil {
+				return "", err
+			}
 		}
 		if !s.cfg.EditOnly {
 			time.Sleep(s.cfg.Sleep)
 			// Delete the comment.
-			if err := s.client.DeleteComment(comment.Data.ID); err != nil {
+			if err := s.client.DeleteComment(opCtx, comment.ID); err != nil {
 				return "", fmt.Errorf("error deleting comment: %w", err)
 			}
+			if err := s.cfg.Checkpoint.Record(ThingTypeComments, comment.Fullname(), ActionDeleted); err != nil {
+				return "", err
+			}
 		}
-		slog.Info("Successfully shredded comment", "permalink", comment.Data.Permalink)
-		if i < len(res.Data.Children)-1 {
-			time.Sleep(s.cfg.Sleep)
+		s.summary.shred(ThingTypeComments)
+		s.cfg.Logger.Info("Successfully shredded comment", "permalink", comment.Permalink)
+		shredded = append(shredded, comment)
+		if i < len(comments)-1 {
+			if err := sleep(ctx, s.cfg.Sleep); err != nil {
+				return "", err
+			}
 		}
 	}
-	return res.Data.After, nil
+	if s.cfg.Verify && len(shredded) > 0 {
+		if err := s.verifyComments(opCtx, shredded); err != nil {
+			return "", err
+		}
+	}
+	return next, nil
 }
 
-func (s *Shredder) shredPosts(after string) (string, error) {
-	res, err := s.client.GetPosts(s.cfg.Username, after)
+func (s *Shredder) shredPosts(ctx context.Context, after string) (string, error) {
+	posts, next, err := s.cfg.Discoverer.Posts(ctx, after)
 	if err != nil {
 		return "", fmt.Errorf("error getting posts: %w", err)
 	}
-	for i, post := range res.Data.Children {
-		// Skip posts younger than the cutoff time.
-		if post.Data.CreatedUTC.After(s.cfg.Before) {
-			slog.Info(
-				"Skipping posts (created after cutoff)",
-				"created", post.Data.CreatedUTC.Time,
-				"permalink", post.Data.Permalink,
-			)
+	opCtx := context.WithoutCancel(ctx)
+	var shredded []reddit.Post
+	for i, post := range posts {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		// Skip posts already shredded by a previous run.
+		action := s.cfg.Checkpoint.Action(ThingTypePosts, post.Fullname())
+		if action == ActionDeleted || (s.cfg.EditOnly && action == ActionEdited) {
+			s.cfg.Logger.Info("Skipping post (already shredded)", "permalink", post.Permalink)
+			s.summary.skip(ThingTypePosts)
+			continue
+		}
+		// Only self posts can be edited, so there's nothing to do with link
+		// posts if they aren't being deleted.
+		if s.cfg.EditOnly && !post.IsSelf {
+			s.cfg.Logger.Info("Skipping post (link posts can't be edited)", "permalink", post.Permalink)
+			s.summary.skip(ThingTypePosts)
 			continue
 		}
-		// Skip posts with a score above the cutoff.
-		if s.cfg.MaxScore != nil && post.Data.Score > *s.cfg.MaxScore {
-			slog.Info(
-				"Skipping post (score > max score)",
-				"score", post.Data.Score,
-				"permalink", post.Data.Permalink,
-			)
+		// Skip posts which the filters keep.
+		if d := s.filter.Filter(postItem(ThingTypePosts, post)); d.Keep {
+			s.cfg.Logger.Info("Skipping post", "reason", d.Reason, "permalink", post.Permalink)
+			s.summary.skip(ThingTypePosts)
 			continue
 		}
+		// Archive the post before it's destroyed. If a previous run already
+		// edited it, the original text is gone (and was archived by that run).
+		if action != ActionEdited {
+			if err := s.archive(postRecord(post)); err != nil {
+				return "", err
+			}
+		}
 		// Dry run; just log what we would do.
 		if s.cfg.DryRun {
-			slog.Info("Would shred post (dry-run)", "permalink", post.Data.Permalink)
+			s.cfg.Logger.Info("Would shred post (dry-run)", "permalink", post.Permalink)
+			s.summary.shred(ThingTypePosts)
 			continue
 		}
-		// Delete the post.
-		if err := s.client.DeletePost(post.Data.ID); err != nil {
-			return "", fmt.Errorf("error deleting post: %w", err)
+		// Overwrite the text of self posts, unless a previous run already
+		// did, so that the original isn't left in caches of the post.
+		if post.IsSelf && action != ActionEdited {
+			if err := s.client.EditPost(opCtx, post.ID, s.cfg.ReplacementComment); err != nil {
+				return "", fmt.Errorf("error editing post: %w", err)
+			}
+			if err := s.cfg.Checkpoint.Record(ThingTypePosts, post.Fullname(), ActionEdited); err != nil {
+				return "", err
+			}
 		}
-		slog.Info("Successfully shredded post", "permalink", post.Data.Permalink)
-		if i < len(res.Data.Children)-1 {
-			time.Sleep(s.cfg.Sleep)
+		if !s.cfg.EditOnly {
+			if post.IsSelf {
+				time.Sleep(s.cfg.Sleep)
+			}
+			// Delete the post.
+			if err := s.client.DeletePost(opCtx, post.ID); err != nil {
+				return "", fmt.Errorf("error deleting post: %w", err)
+			}
+			if err := s.cfg.Checkpoint.Record(ThingTypePosts, post.Fullname(), ActionDeleted); err != nil {
+				return "", err
+			}
+		}
+		s.summary.shred(ThingTypePosts)
+		s.cfg.Logger.Info("Successfully shredded post", "permalink", post.Permalink)
+		shredded = append(shredded, post)
+		if i < len(posts)-1 {
+			if err := sleep(ctx, s.cfg.Sleep); err != nil {
+				return "", err
+			}
+		}
+	}
+	if s.cfg.Verify && len(shredded) > 0 {
+		if err := s.verifyPosts(opCtx, shredded); err != nil {
+			return "", err
+		}
+	}
+	return next, nil
+}
+
+func (s *Shredder) shredSavedComments(ctx context.Context, after string) (string, error) {
+	comments, next, err := s.cfg.Discoverer.SavedComments(ctx, after)
+	if err != nil {
+		return "", fmt.Errorf("error getting saved comments: %w", err)
+	}
+	opCtx := context.WithoutCancel(ctx)
+	for i, comment := range comments {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		// Skip saved comments already unsaved by a previous run.
+		if s.cfg.Checkpoint.Action(ThingTypeSavedComments, comment.Fullname()) == ActionUnsaved {
+			s.cfg.Logger.Info("Skipping saved comment (already unsaved)", "permalink", comment.Permalink)
+			s.summary.skip(ThingTypeSavedComments)
+			continue
+		}
+		// Skip saved comments which the filters keep.
+		if d := s.filter.Filter(commentItem(ThingTypeSavedComments, comment)); d.Keep {
+			s.cfg.Logger.Info("Skipping saved comment", "reason", d.Reason, "permalink", comment.Permalink)
+			s.summary.skip(ThingTypeSavedComments)
+			continue
+		}
+		// Dry run; just log what we would do.
+		if s.cfg.DryRun {
+			s.cfg.Logger.Info("Would unsave comment (dry-run)", "permalink", comment.Permalink)
+			s.summary.shred(ThingTypeSavedComments)
+			continue
+		}
+		// Unsave the comment.
+		if err := s.client.UnsaveComment(opCtx, comment.ID); err != nil {
+			return "", fmt.Errorf("error unsaving comment: %w", err)
+		}
+		if err := s.cfg.Checkpoint.Record(ThingTypeSavedComments, comment.Fullname(), ActionUnsaved); err != nil {
+			return "", err
+		}
+		s.summary.shred(ThingTypeSavedComments)
+		s.cfg.Logger.Info("Successfully unsaved comment", "permalink", comment.Permalink)
+		if i < len(comments)-1 {
+			if err := sleep(ctx, s.cfg.Sleep); err != nil {
+				return "", err
+			}
+		}
+	}
+	return next, nil
+}
+
+func (s *Shredder) shredSavedPosts(ctx context.Context, after string) (string, error) {
+	posts, next, err := s.cfg.Discoverer.SavedPosts(ctx, after)
+	if err != nil {
+		return "", fmt.Errorf("error getting saved posts: %w", err)
+	}
+	opCtx := context.WithoutCancel(ctx)
+	for i, post := range posts {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		// Skip saved posts already unsaved by a previous run.
+		if s.cfg.Checkpoint.Action(ThingTypeSavedPosts, post.Fullname()) == ActionUnsaved {
+			s.cfg.Logger.Info("Skipping saved post (already unsaved)", "permalink", post.Permalink)
+			s.summary.skip(ThingTypeSavedPosts)
+			continue
+		}
+		// Skip saved posts which the filters keep.
+		if d := s.filter.Filter(postItem(ThingTypeSavedPosts, post)); d.Keep {
+			s.cfg.Logger.Info("Skipping saved post", "reason", d.Reason, "permalink", post.Permalink)
+			s.summary.skip(ThingTypeSavedPosts)
+			continue
+		}
+		// Dry run; just log what we would do.
+		if s.cfg.DryRun {
+			s.cfg.Logger.Info("Would unsave post (dry-run)", "permalink", post.Permalink)
+			s.summary.shred(ThingTypeSavedPosts)
+			continue
+		}
+		// Unsave the post.
+		if err := s.client.UnsavePost(opCtx, post.ID); err != nil {
+			return "", fmt.Errorf("error unsaving post: %w", err)
+		}
+		if err := s.cfg.Checkpoint.Record(ThingTypeSavedPosts, post.Fullname(), ActionUnsaved); err != nil {
+			return "", err
+		}
+		s.summary.shred(ThingTypeSavedPosts)
+		s.cfg.Logger.Info("Successfully unsaved post", "permalink", post.Permalink)
+		if i < len(posts)-1 {
+			if err := sleep(ctx, s.cfg.Sleep); err != nil {
+				return "", err
+			}
+		}
+	}
+	return next, nil
+}
+
+// shredVotes returns a pageable which removes the user's votes of the given
+// type, which is either ThingTypeUpvoted or ThingTypeDownvoted. Reddit doesn't
+// say when votes were made, so the age cutoff applies to when the posts were
+// created.
+func (s *Shredder) shredVotes(thingType ThingType) pageable {
+	list, vote := s.client.GetUpvotedPosts, "upvote"
+	if thingType == ThingTypeDownvoted {
+		list, vote = s.client.GetDownvotedPosts, "downvote"
+	}
+	return func(ctx context.Context, after string) (string, error) {
+		res, err := list(ctx, s.cfg.Username, after)
+		if err != nil {
+			return "", fmt.Errorf("error getting %sd posts: %w", vote, err)
+		}
+		posts := res.Items()
+		opCtx := context.WithoutCancel(ctx)
+		for i, post := range posts {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+			// Skip votes already removed by a previous run.
+			if s.cfg.Checkpoint.Action(thingType, post.Fullname()) == ActionUnvoted {
+				s.cfg.Logger.Info("Skipping "+vote+" (already removed)", "permalink", post.Permalink)
+				s.summary.skip(thingType)
+				continue
+			}
+			// Skip votes which the filters keep.
+			if d := s.filter.Filter(postItem(thingType, post)); d.Keep {
+				s.cfg.Logger.Info("Skipping "+vote, "reason", d.Reason, "permalink", post.Permalink)
+				s.summary.skip(thingType)
+				continue
+			}
+			// Dry run; just log what we would do.
+			if s.cfg.DryRun {
+				s.cfg.Logger.Info("Would remove "+vote+" (dry-run)", "permalink", post.Permalink)
+				s.summary.shred(thingType)
+				continue
+			}
+			// Remove the vote.
+			if err := s.client.UnvotePost(opCtx, post.ID); err != nil {
+				return "", fmt.Errorf("error removing %s: %w", vote, err)
+			}
+			if err := s.cfg.Checkpoint.Record(thingType, post.Fullname(), ActionUnvoted); err != nil {
+				return "", err
+			}
+			s.summary.shred(thingType)
+			s.cfg.Logger.Info("Successfully removed "+vote, "permalink", post.Permalink)
+			if i < len(posts)-1 {
+				if err := sleep(ctx, s.cfg.Sleep); err != nil {
+					return "", err
+				}
+			}
+		}
+		return res.Data.After, nil
+	}
+}
+
+func (s *Shredder) shredHidden(ctx context.Context, after string) (string, error) {
+	res, err := s.client.GetHiddenPosts(ctx, s.cfg.Username, after)
+	if err != nil {
+		return "", fmt.Errorf("error getting hidden posts: %w", err)
+	}
+	posts := res.Items()
+	opCtx := context.WithoutCancel(ctx)
+	for i, post := range posts {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		// Skip hidden posts already unhidden by a previous run.
+		if s.cfg.Checkpoint.Action(ThingTypeHidden, post.Fullname()) == ActionUnhidden {
+			s.cfg.Logger.Info("Skipping hidden post (already unhidden)", "permalink", post.Permalink)
+			s.summary.skip(ThingTypeHidden)
+			continue
+		}
+		// Skip hidden posts which the filters keep.
+		if d := s.filter.Filter(postItem(ThingTypeHidden, post)); d.Keep {
+			s.cfg.Logger.Info("Skipping hidden post", "reason", d.Reason, "permalink", post.Permalink)
+			s.summary.skip(ThingTypeHidden)
+			continue
+		}
+		// Dry run; just log what we would do.
+		if s.cfg.DryRun {
+			s.cfg.Logger.Info("Would unhide post (dry-run)", "permalink", post.Permalink)
+			s.summary.shred(ThingTypeHidden)
+			continue
+		}
+		// Unhide the post.
+		if err := s.client.UnhidePost(opCtx, post.ID); err != nil {
+			return "", fmt.Errorf("error unhiding post: %w", err)
+		}
+		if err := s.cfg.Checkpoint.Record(ThingTypeHidden, post.Fullname(), ActionUnhidden); err != nil {
+			return "", err
+		}
+		s.summary.shred(ThingTypeHidden)
+		s.cfg.Logger.Info("Successfully unhid post", "permalink", post.Permalink)
+		if i < len(posts)-1 {
+			if err := sleep(ctx, s.cfg.Sleep); err != nil {
+				return "", err
+			}
 		}
 	}
 	return res.Data.After, nil
 }
 
-func (s *Shredder) shredSavedComments(after string) (string, error) {
-	// TODO: implement -2024-10-30
-	return "", nil
+// shredMessages deletes the user's received messages, followed by their sent
+// messages. Its cursors are of the form "{box}:{after}", where box is "inbox"
+// or "sent", and an empty cursor is the start of the inbox.
+func (s *Shredder) shredMessages(ctx context.Context, cursor string) (string, error) {
+	box, after, _ := strings.Cut(cursor, ":")
+	list := s.client.GetInboxMessages
+	switch box {
+	case "", "inbox":
+		box = "inbox"
+	case "sent":
+		list = s.client.GetSentMessages
+	default:
+		return "", fmt.Errorf("invalid message cursor %q", cursor)
+	}
+	res, err := list(ctx, after)
+	if err != nil {
+		return "", fmt.Errorf("error getting messages: %w", err)
+	}
+	messages := res.Items()
+	opCtx := context.WithoutCancel(ctx)
+	for i, message := range messages {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		// Skip messages already deleted by a previous run.
+		if s.cfg.Checkpoint.Action(ThingTypeMessages, message.Fullname()) == ActionDeleted {
+			s.cfg.Logger.Info("Skipping message (already deleted)", "permalink", message.Permalink())
+			s.summary.skip(ThingTypeMessages)
+			continue
+		}
+		// Skip messages which the filters keep.
+		if d := s.filter.Filter(messageItem(message)); d.Keep {
+			s.cfg.Logger.Info("Skipping message", "reason", d.Reason, "permalink", message.Permalink())
+			s.summary.skip(ThingTypeMessages)
+			continue
+		}
+		// Archive the message before it's deleted.
+		if err := s.archive(messageRecord(message)); err != nil {
+			return "", err
+		}
+		// Dry run; just log what we would do.
+		if s.cfg.DryRun {
+			s.cfg.Logger.Info("Would delete message (dry-run)", "permalink", message.Permalink())
+			s.summary.shred(ThingTypeMessages)
+			continue
+		}
+		// Delete the message.
+		if err := s.client.DeleteMessage(opCtx, message.ID); err != nil {
+			return "", fmt.Errorf("error deleting message: %w", err)
+		}
+		if err := s.cfg.Checkpoint.Record(ThingTypeMessages, message.Fullname(), ActionDeleted); err != nil {
+			return "", err
+		}
+		s.summary.shred(ThingTypeMessages)
+		s.cfg.Logger.Info("Successfully deleted message", "permalink", message.Permalink())
+		if i < len(messages)-1 {
+			if err := sleep(ctx, s.cfg.Sleep); err != nil {
+				return "", err
+			}
+		}
+	}
+	switch {
+	case res.Data.After != "":
+		return box + ":" + res.Data.After, nil
+	case box == "inbox":
+		return "sent:", nil
+	default:
+		return "", nil
+	}
+}
+
+// shredSubscriptions unsubscribes from a page of the user's subscriptions at a
+// time, with a single request for the whole page.
+func (s *Shredder) shredSubscriptions(ctx context.Context, after string) (string, error) {
+	res, err := s.client.GetSubscriptions(ctx, after)
+	if err != nil {
+		return "", fmt.Errorf("error getting subscriptions: %w", err)
+	}
+	var unsubscribe []reddit.Subreddit
+	for _, subreddit := range res.Items() {
+		// Skip subreddits already unsubscribed from by a previous run.
+		if s.cfg.Checkpoint.Action(ThingTypeSubscriptions, subreddit.Fullname()) == ActionUnsubscribed {
+			s.cfg.Logger.Info("Skipping subscription (already unsubscribed)", "subreddit", subreddit.DisplayName)
+			s.summary.skip(ThingTypeSubscriptions)
+			continue
+		}
+		// Skip subreddits which the filters keep.
+		if d := s.filter.Filter(subredditItem(subreddit)); d.Keep {
+			s.cfg.Logger.Info("Skipping subscription", "reason", d.Reason, "subreddit", subreddit.DisplayName)
+			s.summary.skip(ThingTypeSubscriptions)
+			continue
+		}
+		// Dry run; just log what we would do.
+		if s.cfg.DryRun {
+			s.cfg.Logger.Info("Would unsubscribe (dry-run)", "subreddit", subreddit.DisplayName)
+			s.summary.shred(ThingTypeSubscriptions)
+			continue
+		}
+		unsubscribe = append(unsubscribe, subreddit)
+	}
+	if len(unsubscribe) == 0 {
+		return res.Data.After, nil
+	}
+	ids := make([]string, 0, len(unsubscribe))
+	for _, subreddit := range unsubscribe {
+		ids = append(ids, subreddit.ID)
+	}
+	if err := s.client.Unsubscribe(context.WithoutCancel(ctx), ids); err != nil {
+		return "", fmt.Errorf("error unsubscribing: %w", err)
+	}
+	for _, subreddit := range unsubscribe {
+		if err := s.cfg.Checkpoint.Record(ThingTypeSubscriptions, subreddit.Fullname(), ActionUnsubscribed); err != nil {
+			return "", err
+		}
+		s.summary.shred(ThingTypeSubscriptions)
+		s.cfg.Logger.Info("Successfully unsubscribed", "subreddit", subreddit.DisplayName)
+	}
+	return res.Data.After, nil
 }
 
-func (s *Shredder) shredSavedPosts(after string) (string, error) {
-	// TODO: implement -2024-10-30
+// shredFriends removes all of the user's friends. Reddit returns the entire
+// friend list at once, so there is never a next page.
+func (s *Shredder) shredFriends(ctx context.Context, _ string) (string, error) {
+	friends, err := s.client.GetFriends(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error getting friends: %w", err)
+	}
+	opCtx := context.WithoutCancel(ctx)
+	for i, friend := range friends {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		// Skip friends which the filters keep.
+		if d := s.filter.Filter(friendItem(friend)); d.Keep {
+			s.cfg.Logger.Info("Skipping friend", "reason", d.Reason, "friend", friend.Name)
+			s.summary.skip(ThingTypeFriends)
+			continue
+		}
+		// Dry run; just log what we would do.
+		if s.cfg.DryRun {
+			s.cfg.Logger.Info("Would remove friend (dry-run)", "friend", friend.Name)
+			s.summary.shred(ThingTypeFriends)
+			continue
+		}
+		// Unfriend the user.
+		if err := s.client.Unfriend(opCtx, friend.Name); err != nil {
+			return "", fmt.Errorf("error removing friend: %w", err)
+		}
+		if err := s.cfg.Checkpoint.Record(ThingTypeFriends, friend.ID, ActionUnfriended); err != nil {
+			return "", err
+		}
+		s.summary.shred(ThingTypeFriends)
+		s.cfg.Logger.Info("Successfully removed friend", "friend", friend.Name)
+		if i < len(friends)-1 {
+			if err := sleep(ctx, s.cfg.Sleep); err != nil {
+				return "", err
+			}
+		}
+	}
 	return "", nil
 }
 
 // TODO: doc -2024-10-31
-type pageable func(cursor string) (next string, err error)
+type pageable func(ctx context.Context, cursor string) (next string, err error)
 
-// TODO: doc -2024-10-31
-func (s *Shredder) pager(fn pageable) (err error) {
-	cursor := ""
+// pager calls fn with successive cursors until it returns an empty cursor. The
+// cursor of the next page is recorded in the checkpoint after each page, and
+// paging starts from the recorded cursor of the given thing type, if any.
+func (s *Shredder) pager(ctx context.Context, thingType ThingType, fn pageable) (err error) {
+	source := s.source(thingType)
+	cursor := s.cfg.Checkpoint.Cursor(thingType, source)
+	if cursor != "" {
+		s.cfg.Logger.Info("Resuming from checkpoint", "thingType", thingType, "cursor", cursor)
+	}
 	for {
-		cursor, err = fn(cursor)
+		cursor, err = fn(ctx, cursor)
 		if err != nil {
 			return err
 		}
+		if err := s.cfg.Checkpoint.SetCursor(thingType, source, cursor); err != nil {
+			return err
+		}
 		if cursor == "" {
 			// Done - no more items to process.
 			return nil
 		}
-		// Sleep for a bit to avoid rate limiting.
-		time.Sleep(s.cfg.Sleep)
+		// Wait for the extra delay between pages, if any.
+		if err := sleep(ctx, s.cfg.Sleep); err != nil {
+			return err
+		}
+	}
+}
+
+// source returns the name of where things of the given type are discovered
+// from, which determines what their cursors mean.
+func (s *Shredder) source(thingType ThingType) string {
+	switch thingType {
+	case ThingTypeComments, ThingTypePosts, ThingTypeSavedComments, ThingTypeSavedPosts:
+	default:
+		return "listing"
+	}
+	switch s.cfg.Discoverer.(type) {
+	case *sweepDiscoverer:
+		return "sweep"
+	case *exportDiscoverer:
+		return "export"
+	default:
+		return "listing"
+	}
+}
+
+// Summary returns counts of the things that have been shredded and skipped so
+// far.
+func (s *Shredder) Summary() Summary {
+	return s.summary.clone()
+}
+
+// sleep pauses for the given duration, or until the context is done, in which
+// case the context's error is returned.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
 }
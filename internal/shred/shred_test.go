@@ -0,0 +1,631 @@
+package shred
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccampo133/shreddit-go/internal/archive"
+	"github.com/ccampo133/shreddit-go/internal/reddit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSleep_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	require.ErrorIs(t, sleep(ctx, time.Minute), context.Canceled)
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestNewShredder_NoSleepByDefault(t *testing.T) {
+	// The client paces requests itself, so there's no extra delay unless one
+	// is configured.
+	s := NewShredder(nil, Config{})
+	require.Zero(t, s.cfg.Sleep)
+}
+
+func TestShredder_pager_StopsWhenCancelled(t *testing.T) {
+	s := NewShredder(nil, Config{Discoverer: NewExportDiscoverer(nil, nil), Sleep: time.Minute})
+	ctx, cancel := context.WithCancel(context.Background())
+	pages := 0
+	err := s.pager(
+		ctx, ThingTypeComments, func(_ context.Context, _ string) (string, error) {
+			pages++
+			cancel()
+			return "next", nil
+		},
+	)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, pages)
+	// The cursor of the next page was recorded, so the stage can be resumed.
+	require.Equal(t, "next", s.cfg.Checkpoint.Cursor(ThingTypeComments, "export"))
+}
+
+func TestShredder_pager_IgnoresCursorFromOtherSource(t *testing.T) {
+	cp := NewCheckpoint("")
+	require.NoError(t, cp.SetCursor(ThingTypeComments, "sweep", "0:t1_abc"))
+	require.NoError(t, cp.SetCursor(ThingTypePosts, "export", "t3_def"))
+	s := NewShredder(nil, Config{Discoverer: NewExportDiscoverer(nil, nil), Checkpoint: cp})
+
+	// A cursor from a sweep means nothing to an export, so it starts over.
+	var cursors []string
+	err := s.pager(
+		context.Background(), ThingTypeComments, func(_ context.Context, cursor string) (string, error) {
+			cursors = append(cursors, cursor)
+			return "", nil
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{""}, cursors)
+
+	// A cursor from the same source is resumed from.
+	cursors = nil
+	err = s.pager(
+		context.Background(), ThingTypePosts, func(_ context.Context, cursor string) (string, error) {
+			cursors = append(cursors, cursor)
+			return "", nil
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"t3_def"}, cursors)
+}
+
+func TestSummary_LogValue(t *testing.T) {
+	sum := newSummary()
+	sum.shred(ThingTypeComments)
+	sum.shred(ThingTypeComments)
+	sum.skip(ThingTypePosts)
+	require.Equal(
+		t,
+		"[comments=[shredded=2 skipped=0] posts=[shredded=0 skipped=1]]",
+		sum.LogValue().String(),
+	)
+}
+
+// newTestClient returns a client which talks to a test server with the given
+// handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *reddit.Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		"/api/v1/access_token", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "test_token", "token_type": "bearer", "expires_in": 3600}`))
+		},
+	)
+	mux.HandleFunc("/", handler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	client, err := reddit.NewClient(
+		context.Background(), reddit.Config{
+			BaseURL:      server.URL,
+			ClientID:     "test_client_id",
+			ClientSecret: "test_client_secret",
+			Username:     "test_username",
+			Password:     "test_password",
+		},
+	)
+	require.NoError(t, err)
+	return client
+}
+
+// newTestShredder returns a Shredder whose client talks to a test server with
+// the given handler.
+func newTestShredder(t *testing.T, handler http.HandlerFunc, cfg Config) *Shredder {
+	t.Helper()
+	return NewShredder(newTestClient(t, handler), cfg)
+}
+
+func TestShredder_shredVotes(t *testing.T) {
+	tests := []struct {
+		name        string
+		thingType   ThingType
+		dryRun      bool
+		wantUnvotes []string
+	}{
+		{name: "upvoted", thingType: ThingTypeUpvoted, wantUnvotes: []string{"t3_old"}},
+		{name: "downvoted", thingType: ThingTypeDownvoted, wantUnvotes: []string{"t3_old"}},
+		{name: "dry run", thingType: ThingTypeUpvoted, dryRun: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var unvotes []string
+				s := newTestShredder(
+					t, func(w http.ResponseWriter, r *http.Request) {
+						switch r.URL.Path {
+						case "/user/test_username/" + string(tt.thingType) + ".json":
+							w.Header().Set("Content-Type", "application/json")
+							_, _ = w.Write(
+								[]byte(`{"data": {"children": [` +
+									`{"kind": "t3", "data": {"id": "old", "created_utc": 1000}}, ` +
+									`{"kind": "t3", "data": {"id": "new", "created_utc": 2000000000}}]}}`),
+							)
+						case "/api/vote":
+							require.NoError(t, r.ParseForm())
+							require.Equal(t, "0", r.PostForm.Get("dir"))
+							unvotes = append(unvotes, r.PostForm.Get("id"))
+							_, _ = w.Write([]byte(`{}`))
+						default:
+							t.Fatalf("unexpected request to %s", r.URL.Path)
+						}
+					},
+					Config{Username: "test_username", DryRun: tt.dryRun, Before: time.Unix(1000000, 0)},
+				)
+				next, err := s.shredVotes(tt.thingType)(context.Background(), "")
+				require.NoError(t, err)
+				require.Empty(t, next)
+				require.Equal(t, tt.wantUnvotes, unvotes)
+				require.Equal(t, 1, s.Summary().Shredded[tt.thingType])
+				require.Equal(t, 1, s.Summary().Skipped[tt.thingType])
+			},
+		)
+	}
+}
+
+func TestShredder_shredHidden(t *testing.T) {
+	var unhidden []string
+	s := newTestShredder(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/user/test_username/hidden.json":
+				require.Equal(t, "t3_prev", r.URL.Query().Get("after"))
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write(
+					[]byte(`{"data": {"after": "t3_b", "children": [` +
+						`{"kind": "t3", "data": {"id": "a", "subreddit": "golang"}}, ` +
+						`{"kind": "t3", "data": {"id": "b", "subreddit": "pics"}}]}}`),
+				)
+			case "/api/unhide":
+				require.NoError(t, r.ParseForm())
+				unhidden = append(unhidden, r.PostForm.Get("id"))
+				_, _ = w.Write([]byte(`{}`))
+			default:
+				t.Fatalf("unexpected request to %s", r.URL.Path)
+			}
+		},
+		Config{Username: "test_username", KeepSubreddits: []string{"golang"}},
+	)
+	next, err := s.shredHidden(context.Background(), "t3_prev")
+	require.NoError(t, err)
+	require.Equal(t, "t3_b", next)
+	require.Equal(t, []string{"t3_b"}, unhidden)
+	require.Equal(t, 1, s.Summary().Shredded[ThingTypeHidden])
+	require.Equal(t, 1, s.Summary().Skipped[ThingTypeHidden])
+}
+
+func TestShredder_shredMessages(t *testing.T) {
+	var deleted []string
+	s := newTestShredder(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/message/inbox.json":
+				if r.URL.Query().Get("after") == "" {
+					_, _ = w.Write(
+						[]byte(`{"data": {"after": "t4_b", "children": [` +
+							`{"kind": "t4", "data": {"id": "a", "created_utc": 1000}}, ` +
+							`{"kind": "t4", "data": {"id": "b", "created_utc": 2000000000}}]}}`),
+					)
+					return
+				}
+				require.Equal(t, "t4_b", r.URL.Query().Get("after"))
+				_, _ = w.Write([]byte(`{"data": {"children": []}}`))
+			case "/message/sent.json":
+				_, _ = w.Write([]byte(`{"data": {"children": [{"kind": "t4", "data": {"id": "c", "created_utc": 1000}}]}}`))
+			case "/api/del_msg":
+				require.NoError(t, r.ParseForm())
+				deleted = append(deleted, r.PostForm.Get("id"))
+				_, _ = w.Write([]byte(`{}`))
+			default:
+				t.Fatalf("unexpected request to %s", r.URL.Path)
+			}
+		},
+		Config{Before: time.Unix(1000000, 0)},
+	)
+	var cursors []string
+	cursor := ""
+	for {
+		next, err := s.shredMessages(context.Background(), cursor)
+		require.NoError(t, err)
+		cursors = append(cursors, next)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	require.Equal(t, []string{"inbox:t4_b", "sent:", ""}, cursors)
+	// The newer message is kept.
+	require.Equal(t, []string{"t4_a", "t4_c"}, deleted)
+	require.Equal(t, 2, s.Summary().Shredded[ThingTypeMessages])
+	require.Equal(t, 1, s.Summary().Skipped[ThingTypeMessages])
+
+	_, err := s.shredMessages(context.Background(), "foo:t4_a")
+	require.Error(t, err)
+}
+
+func TestShredder_shredSubscriptions(t *testing.T) {
+	var unsubscribed []string
+	s := newTestShredder(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/subreddits/mine/subscriber.json":
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write(
+					[]byte(`{"data": {"after": "t5_c", "children": [` +
+						`{"kind": "t5", "data": {"id": "a", "display_name": "golang"}}, ` +
+						`{"kind": "t5", "data": {"id": "b", "display_name": "GoPro"}}, ` +
+						`{"kind": "t5", "data": {"id": "c", "display_name": "pics"}}]}}`),
+				)
+			case "/api/subscribe":
+				require.NoError(t, r.ParseForm())
+				require.Equal(t, "unsub", r.PostForm.Get("action"))
+				unsubscribed = append(unsubscribed, r.PostForm.Get("sr"))
+				_, _ = w.Write([]byte(`{}`))
+			default:
+				t.Fatalf("unexpected request to %s", r.URL.Path)
+			}
+		},
+		Config{KeepSubreddits: []string{"golang"}},
+	)
+	next, err := s.shredSubscriptions(context.Background(), "")
+	require.NoError(t, err)
+	require.Equal(t, "t5_c", next)
+	// Both subreddits are unsubscribed from in one request.
+	require.Equal(t, []string{"t5_b,t5_c"}, unsubscribed)
+	require.Equal(t, 2, s.Summary().Shredded[ThingTypeSubscriptions])
+	require.Equal(t, 1, s.Summary().Skipped[ThingTypeSubscriptions])
+	require.Equal(t, ActionUnsubscribed, s.cfg.Checkpoint.Action(ThingTypeSubscriptions, "t5_b"))
+}
+
+// recordingArchive is an archive.Writer which keeps the fullnames of the
+// records written to it.
+type recordingArchive struct {
+	fullnames []string
+}
+
+func (a *recordingArchive) Write(rec archive.Record) error {
+	a.fullnames = append(a.fullnames, rec.Fullname)
+	return nil
+}
+
+func (a *recordingArchive) Close() error {
+	return nil
+}
+
+func TestShredder_shredComments(t *testing.T) {
+	tests := []struct {
+		name         string
+		dryRun       bool
+		action       Action
+		wantEdits    []string
+		wantDeletes  []string
+		wantArchived []string
+		wantShredded int
+		wantSkipped  int
+	}{
+		{
+			name:         "comments are archived and edited before they're deleted",
+			wantEdits:    []string{"t1_abc"},
+			wantDeletes:  []string{"t1_abc"},
+			wantArchived: []string{"t1_abc"},
+			wantShredded: 1,
+		},
+		{
+			name:         "dry run",
+			dryRun:       true,
+			wantArchived: []string{"t1_abc"},
+			wantShredded: 1,
+		},
+		{
+			name:        "comments already deleted by a previous run are skipped",
+			action:      ActionDeleted,
+			wantSkipped: 1,
+		},
+		{
+			// The original was archived when the previous run edited it.
+			name:         "comments already edited by a previous run are only deleted",
+			action:       ActionEdited,
+			wantDeletes:  []string{"t1_abc"},
+			wantShredded: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var edits, deletes []string
+				cp := NewCheckpoint("")
+				if tt.action != "" {
+					require.NoError(t, cp.Record(ThingTypeComments, "t1_abc", tt.action))
+				}
+				arch := &recordingArchive{}
+				s := newTestShredder(
+					t, func(w http.ResponseWriter, r *http.Request) {
+						w.Header().Set("Content-Type", "application/json")
+						switch r.URL.Path {
+						case "/user/test_username/comments.json":
+							_, _ = w.Write([]byte(`{"data": {"children": [{"kind": "t1", "data": {"id": "abc"}}]}}`))
+						case "/api/editusertext":
+							require.NoError(t, r.ParseForm())
+							edits = append(edits, r.PostForm.Get("thing_id"))
+							_, _ = w.Write([]byte(`{"jquery": [], "success": true}`))
+						case "/api/del":
+							require.NoError(t, r.ParseForm())
+							deletes = append(deletes, r.PostForm.Get("id"))
+							_, _ = w.Write([]byte(`{}`))
+						default:
+							t.Fatalf("unexpected request to %s", r.URL.Path)
+						}
+					},
+					Config{Username: "test_username", DryRun: tt.dryRun, Checkpoint: cp, Archive: arch},
+				)
+				_, err := s.shredComments(context.Background(), "")
+				require.NoError(t, err)
+				require.Equal(t, tt.wantEdits, edits)
+				require.Equal(t, tt.wantDeletes, deletes)
+				require.Equal(t, tt.wantArchived, arch.fullnames)
+				require.Equal(t, tt.wantShredded, s.Summary().Shredded[ThingTypeComments])
+				require.Equal(t, tt.wantSkipped, s.Summary().Skipped[ThingTypeComments])
+			},
+		)
+	}
+}
+
+func TestShredder_shredPosts(t *testing.T) {
+	tests := []struct {
+		name        string
+		editOnly    bool
+		wantEdits   []string
+		wantDeletes []string
+		wantSkipped int
+	}{
+		{
+			name:        "self posts are edited before they're deleted",
+			wantEdits:   []string{"t3_self"},
+			wantDeletes: []string{"t3_self", "t3_link"},
+		},
+		{
+			name:        "edit only",
+			editOnly:    true,
+			wantEdits:   []string{"t3_self"},
+			wantSkipped: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var requests []string
+				var edits, deletes []string
+				s := newTestShredder(
+					t, func(w http.ResponseWriter, r *http.Request) {
+						requests = append(requests, r.URL.Path)
+						w.Header().Set("Content-Type", "application/json")
+						switch r.URL.Path {
+						case "/user/test_username/submitted.json":
+							_, _ = w.Write(
+								[]byte(`{"data": {"children": [` +
+									`{"kind": "t3", "data": {"id": "self", "is_self": true, "selftext": "secret"}}, ` +
+									`{"kind": "t3", "data": {"id": "link", "is_self": false}}]}}`),
+							)
+						case "/api/editusertext":
+							require.NoError(t, r.ParseForm())
+							require.Equal(t, "[deleted]", r.PostForm.Get("text"))
+							edits = append(edits, r.PostForm.Get("thing_id"))
+							_, _ = w.Write([]byte(`{"jquery": [], "success": true}`))
+						case "/api/del":
+							require.NoError(t, r.ParseForm())
+							deletes = append(deletes, r.PostForm.Get("id"))
+							_, _ = w.Write([]byte(`{}`))
+						default:
+							t.Fatalf("unexpected request to %s", r.URL.Path)
+						}
+					},
+					Config{Username: "test_username", EditOnly: tt.editOnly},
+				)
+				_, err := s.shredPosts(context.Background(), "")
+				require.NoError(t, err)
+				require.Equal(t, tt.wantEdits, edits)
+				require.Equal(t, tt.wantDeletes, deletes)
+				require.Equal(t, tt.wantSkipped, s.Summary().Skipped[ThingTypePosts])
+				if !tt.editOnly {
+					// The self post is edited before it's deleted.
+					require.Equal(
+						t,
+						[]string{"/user/test_username/submitted.json", "/api/editusertext", "/api/del", "/api/del"},
+						requests,
+					)
+				}
+			},
+		)
+	}
+}
+
+func TestShredder_ResumeAcrossStages(t *testing.T) {
+	// Reddit upvotes users' own posts, so the same post is shredded by both
+	// the posts and upvoted stages.
+	var edits []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/user/test_username/submitted.json", "/user/test_username/upvoted.json":
+			_, _ = w.Write(
+				[]byte(`{"data": {"children": [{"kind": "t3", "data": {"id": "self", "is_self": true, "selftext": "secret"}}]}}`),
+			)
+		case "/api/editusertext":
+			require.NoError(t, r.ParseForm())
+			edits = append(edits, r.PostForm.Get("thing_id"))
+			_, _ = w.Write([]byte(`{"jquery": [], "success": true}`))
+		case "/api/vote":
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			t.Fatalf("unexpected request to %s", r.URL.Path)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "state.json")
+	arch := &recordingArchive{}
+	cfg := Config{Username: "test_username", EditOnly: true, Checkpoint: NewCheckpoint(path), Archive: arch}
+	s := newTestShredder(t, handler, cfg)
+	_, err := s.shredPosts(context.Background(), "")
+	require.NoError(t, err)
+	_, err = s.shredVotes(ThingTypeUpvoted)(context.Background(), "")
+	require.NoError(t, err)
+	require.NoError(t, cfg.Checkpoint.Close())
+
+	// Removing the upvote doesn't make a resumed run edit the post again.
+	cfg.Checkpoint, err = LoadCheckpoint(path)
+	require.NoError(t, err)
+	s = newTestShredder(t, handler, cfg)
+	_, err = s.shredPosts(context.Background(), "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"t3_self"}, edits)
+	require.Equal(t, []string{"t3_self"}, arch.fullnames)
+	require.Equal(t, 1, s.Summary().Skipped[ThingTypePosts])
+}
+
+func TestShredder_shredFriends(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           Config
+		wantUnfriends []string
+		wantShredded  int
+		wantSkipped   int
+	}{
+		{
+			name:          "unfriends",
+			cfg:           Config{Before: time.Unix(3000000000, 0)},
+			wantUnfriends: []string{"old", "new"},
+			wantShredded:  2,
+		},
+		{
+			name:         "dry run",
+			cfg:          Config{Before: time.Unix(3000000000, 0), DryRun: true},
+			wantShredded: 2,
+		},
+		{
+			name:          "friends made after the cutoff are kept",
+			cfg:           Config{Before: time.Unix(1000000, 0)},
+			wantUnfriends: []string{"old"},
+			wantShredded:  1,
+			wantSkipped:   1,
+		},
+		{
+			name: "filters",
+			cfg: Config{
+				Before: time.Unix(3000000000, 0),
+				Filters: []Filter{
+					FilterFunc(
+						func(item Item) Decision {
+							return Decision{Keep: item.Permalink == "/user/old", Reason: "test"}
+						},
+					),
+				},
+			},
+			wantUnfriends: []string{"new"},
+			wantShredded:  1,
+			wantSkipped:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var unfriends []string
+				s := newTestShredder(
+					t, func(w http.ResponseWriter, r *http.Request) {
+						switch {
+						case r.Method == http.MethodGet && r.URL.Path == "/api/v1/me/friends":
+							w.Header().Set("Content-Type", "application/json")
+							_, _ = w.Write(
+								[]byte(`{"data": {"children": [` +
+									`{"id": "t2_old", "name": "old", "date": 1000}, ` +
+									`{"id": "t2_new", "name": "new", "date": 2000000000}]}}`),
+							)
+						case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/api/v1/me/friends/"):
+							unfriends = append(unfriends, strings.TrimPrefix(r.URL.Path, "/api/v1/me/friends/"))
+							w.WriteHeader(http.StatusNoContent)
+						default:
+							t.Fatalf("unexpected request to %s %s", r.Method, r.URL.Path)
+						}
+					},
+					tt.cfg,
+				)
+				next, err := s.shredFriends(context.Background(), "")
+				require.NoError(t, err)
+				require.Empty(t, next)
+				require.Equal(t, tt.wantUnfriends, unfriends)
+				require.Equal(t, tt.wantShredded, s.Summary().Shredded[ThingTypeFriends])
+				require.Equal(t, tt.wantSkipped, s.Summary().Skipped[ThingTypeFriends])
+			},
+		)
+	}
+}
+
+func TestShredder_shredSaved(t *testing.T) {
+	tests := []struct {
+		name        string
+		thingType   ThingType
+		dryRun      bool
+		wantUnsaves []string
+	}{
+		{name: "saved comments", thingType: ThingTypeSavedComments, wantUnsaves: []string{"t1_old"}},
+		{name: "saved posts", thingType: ThingTypeSavedPosts, wantUnsaves: []string{"t3_old"}},
+		{name: "dry run", thingType: ThingTypeSavedComments, dryRun: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				kind, listingType := "t1", "comments"
+				if tt.thingType == ThingTypeSavedPosts {
+					kind, listingType = "t3", "links"
+				}
+				var unsaves []string
+				maxScore := 10
+				s := newTestShredder(
+					t, func(w http.ResponseWriter, r *http.Request) {
+						switch r.URL.Path {
+						case "/user/test_username/saved.json":
+							require.Equal(t, listingType, r.URL.Query().Get("type"))
+							// Only "old" is both old enough and has a low
+							// enough score to be unsaved.
+							w.Header().Set("Content-Type", "application/json")
+							_, _ = fmt.Fprintf(
+								w, `{"data": {"after": "%[1]s_popular", "children": [`+
+									`{"kind": "%[1]s", "data": {"id": "old", "score": 1, "created_utc": 1000}}, `+
+									`{"kind": "%[1]s", "data": {"id": "new", "score": 1, "created_utc": 2000000000}}, `+
+									`{"kind": "%[1]s", "data": {"id": "popular", "score": 100, "created_utc": 1000}}]}}`,
+								kind,
+							)
+						case "/api/unsave":
+							require.NoError(t, r.ParseForm())
+							unsaves = append(unsaves, r.PostForm.Get("id"))
+							_, _ = w.Write([]byte(`{}`))
+						default:
+							t.Fatalf("unexpected request to %s", r.URL.Path)
+						}
+					},
+					Config{
+						Username: "test_username",
+						DryRun:   tt.dryRun,
+						Before:   time.Unix(1000000, 0),
+						MaxScore: &maxScore,
+					},
+				)
+				shred := s.shredSavedComments
+				if tt.thingType == ThingTypeSavedPosts {
+					shred = s.shredSavedPosts
+				}
+				next, err := shred(context.Background(), "")
+				require.NoError(t, err)
+				require.Equal(t, kind+"_popular", next)
+				require.Equal(t, tt.wantUnsaves, unsaves)
+				require.Equal(t, 1, s.Summary().Shredded[tt.thingType])
+				require.Equal(t, 2, s.Summary().Skipped[tt.thingType])
+			},
+		)
+	}
+}
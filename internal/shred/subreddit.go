@@ -0,0 +1,51 @@
+package shred
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// SubredditFilter keeps items which aren't in a subreddit matching any of
+// Patterns. See matchSubreddit for how patterns are matched; invalid patterns
+// never match.
+type SubredditFilter struct {
+	Patterns []string
+}
+
+func (f SubredditFilter) Filter(item Item) Decision {
+	if pattern, ok := matchSubreddit(f.Patterns, item.Subreddit); ok {
+		return Decision{Keep: false, Reason: fmt.Sprintf("subreddit %q matches %q", item.Subreddit, pattern)}
+	}
+	return Decision{Keep: true, Reason: fmt.Sprintf("subreddit %q matches no patterns", item.Subreddit)}
+}
+
+// matchSubreddit returns the first of the glob patterns (see path.Match) which
+// matches the subreddit, if any. Matching is case-insensitive, and an "r/"
+// prefix on either the patterns or the subreddit is ignored.
+func matchSubreddit(patterns []string, subreddit string) (string, bool) {
+	subreddit = normalizeSubreddit(subreddit)
+	for _, pattern := range patterns {
+		// Invalid patterns are rejected by ValidateSubredditPatterns.
+		if ok, _ := path.Match(normalizeSubreddit(pattern), subreddit); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// ValidateSubredditPatterns checks that each of the glob patterns is valid.
+func ValidateSubredditPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(normalizeSubreddit(pattern), ""); err != nil {
+			return fmt.Errorf("invalid subreddit pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func normalizeSubreddit(subreddit string) string {
+	subreddit = strings.ToLower(strings.TrimSpace(subreddit))
+	subreddit = strings.TrimPrefix(subreddit, "/")
+	return strings.TrimPrefix(subreddit, "r/")
+}
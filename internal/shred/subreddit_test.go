@@ -0,0 +1,59 @@
+package shred
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchSubreddit(t *testing.T) {
+	patterns := []string{"golang", "r/Rust*"}
+	for _, sub := range []string{"golang", "GoLang", "rust", "rust_gamedev"} {
+		_, ok := matchSubreddit(patterns, sub)
+		require.True(t, ok, sub)
+	}
+	pattern, _ := matchSubreddit(patterns, "rust_gamedev")
+	require.Equal(t, "r/Rust*", pattern)
+	_, ok := matchSubreddit(patterns, "python")
+	require.False(t, ok)
+	_, ok = matchSubreddit(nil, "golang")
+	require.False(t, ok)
+}
+
+func TestSubredditFilter(t *testing.T) {
+	f := SubredditFilter{Patterns: []string{"go*"}}
+	d := f.Filter(Item{Type: ThingTypeComments, Subreddit: "golang"})
+	require.Equal(t, Decision{Keep: false, Reason: `subreddit "golang" matches "go*"`}, d)
+	d = f.Filter(Item{Type: ThingTypeComments, Subreddit: "python"})
+	require.Equal(t, Decision{Keep: true, Reason: `subreddit "python" matches no patterns`}, d)
+}
+
+func TestConfig_filters_Subreddits(t *testing.T) {
+	cfg := Config{KeepSubreddits: []string{"golang"}}
+	f := All(cfg.filters()...)
+	require.True(t, f.Filter(Item{Type: ThingTypeComments, Subreddit: "golang"}).Keep)
+	require.False(t, f.Filter(Item{Type: ThingTypeComments, Subreddit: "python"}).Keep)
+
+	cfg = Config{OnlySubreddits: []string{"python", "go*"}, KeepSubreddits: []string{"golang"}}
+	f = All(cfg.filters()...)
+	require.False(t, f.Filter(Item{Type: ThingTypeComments, Subreddit: "python"}).Keep)
+	require.False(t, f.Filter(Item{Type: ThingTypeComments, Subreddit: "gophers"}).Keep)
+	require.True(t, f.Filter(Item{Type: ThingTypeComments, Subreddit: "rust"}).Keep)
+	// KeepSubreddits takes precedence.
+	d := f.Filter(Item{Type: ThingTypeComments, Subreddit: "golang"})
+	require.True(t, d.Keep)
+	require.Equal(t, `type is comments; subreddit "golang" matches "golang"`, d.Reason)
+
+	// Messages and friends aren't in subreddits, so they aren't kept.
+	require.False(t, f.Filter(Item{Type: ThingTypeMessages}).Keep)
+	require.False(t, f.Filter(Item{Type: ThingTypeFriends}).Keep)
+	require.True(t, f.Filter(Item{Type: ThingTypeComments}).Keep)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := Config{KeepSubreddits: []string{"golang", "[rust"}}
+	require.ErrorContains(t, cfg.Validate(), `invalid subreddit pattern "[rust"`)
+
+	cfg = Config{OnlySubreddits: []string{"go*"}}
+	require.NoError(t, cfg.Validate())
+}
@@ -0,0 +1,63 @@
+package shred
+
+import (
+	"log/slog"
+	"maps"
+)
+
+// Summary counts the things that a Shredder has shredded and skipped, by thing
+// type. During a dry run, things that would have been shredded are counted as
+// shredded.
+type Summary struct {
+	Shredded map[ThingType]int
+	Skipped  map[ThingType]int
+	// Unverified counts shredded things which were found not to have been
+	// shredded after all when verifying, even after retrying.
+	Unverified map[ThingType]int
+}
+
+func newSummary() Summary {
+	return Summary{
+		Shredded:   make(map[ThingType]int),
+		Skipped:    make(map[ThingType]int),
+		Unverified: make(map[ThingType]int),
+	}
+}
+
+func (s *Summary) shred(thingType ThingType) {
+	s.Shredded[thingType]++
+}
+
+func (s *Summary) skip(thingType ThingType) {
+	s.Skipped[thingType]++
+}
+
+func (s *Summary) unverify(thingType ThingType) {
+	s.Unverified[thingType]++
+}
+
+func (s *Summary) clone() Summary {
+	return Summary{
+		Shredded:   maps.Clone(s.Shredded),
+		Skipped:    maps.Clone(s.Skipped),
+		Unverified: maps.Clone(s.Unverified),
+	}
+}
+
+// LogValue implements slog.LogValuer, logging the counts for each thing type
+// that has any. Unverified counts are only included if there are any.
+func (s Summary) LogValue() slog.Value {
+	var attrs []slog.Attr
+	for _, t := range ThingTypes {
+		shredded, skipped := s.Shredded[t], s.Skipped[t]
+		if shredded == 0 && skipped == 0 {
+			continue
+		}
+		counts := []any{slog.Int("shredded", shredded), slog.Int("skipped", skipped)}
+		if unverified := s.Unverified[t]; unverified > 0 {
+			counts = append(counts, slog.Int("unverified", unverified))
+		}
+		attrs = append(attrs, slog.Group(string(t), counts...))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,158 @@
+package shred
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ThingType is a type of Reddit "thing" that the Shredder knows how to
+// remove, e.g. comments or saved posts.
+type ThingType string
+
+const (
+	ThingTypePosts         ThingType = "posts"
+	ThingTypeComments      ThingType = "comments"
+	ThingTypeFriends       ThingType = "friends"
+	ThingTypeSavedPosts    ThingType = "saved-posts"
+	ThingTypeSavedComments ThingType = "saved-comments"
+	ThingTypeUpvoted       ThingType = "upvoted"
+	ThingTypeDownvoted     ThingType = "downvoted"
+	ThingTypeHidden        ThingType = "hidden"
+	ThingTypeMessages      ThingType = "messages"
+	ThingTypeSubscriptions ThingType = "subscriptions"
+)
+
+// ThingTypes contains every supported ThingType, in the order that the
+// Shredder processes them.
+var ThingTypes = []ThingType{
+	ThingTypeComments,
+	ThingTypePosts,
+	ThingTypeSavedComments,
+	ThingTypeSavedPosts,
+	ThingTypeUpvoted,
+	ThingTypeDownvoted,
+	ThingTypeHidden,
+	ThingTypeMessages,
+	ThingTypeSubscriptions,
+	ThingTypeFriends,
+}
+
+// SubredditThingTypes are the thing types which are in a subreddit, and so can
+// be filtered by it. Private messages and friends aren't.
+var SubredditThingTypes = []ThingType{
+	ThingTypeComments,
+	ThingTypePosts,
+	ThingTypeSavedComments,
+	ThingTypeSavedPosts,
+	ThingTypeUpvoted,
+	ThingTypeDownvoted,
+	ThingTypeHidden,
+	ThingTypeSubscriptions,
+}
+
+// DefaultThingTypes are the thing types which are shredded unless others are
+// chosen. Types added since the original set (e.g. votes) are left out, so
+// that upgrading doesn't change what existing setups remove.
+var DefaultThingTypes = []ThingType{
+	ThingTypeComments,
+	ThingTypePosts,
+	ThingTypeSavedComments,
+	ThingTypeSavedPosts,
+	ThingTypeFriends,
+}
+
+// SelectThingTypes returns the thing types to shred, given the types that were
+// chosen explicitly and the types named by the config file's rules, either of
+// which may be nil. Rules only apply to the types they name, so if both are
+// given, only the types in both are shredded. If neither is, the result is
+// DefaultThingTypes.
+func SelectThingTypes(chosen, ruleTypes []ThingType) []ThingType {
+	switch {
+	case chosen == nil && ruleTypes == nil:
+		return DefaultThingTypes
+	case chosen == nil:
+		return ruleTypes
+	case ruleTypes == nil:
+		return chosen
+	}
+	var types []ThingType
+	for _, t := range chosen {
+		if slices.Contains(ruleTypes, t) {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// ParseThingType parses a thing type from its string representation. An error
+// is returned if the value is not one of ThingTypes.
+func ParseThingType(s string) (ThingType, error) {
+	for _, t := range ThingTypes {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown thing type %q (must be one of: %s)", s, JoinThingTypes(ThingTypes))
+}
+
+// ParseThingTypes parses each of the given strings with ParseThingType.
+func ParseThingTypes(values []string) ([]ThingType, error) {
+	types := make([]ThingType, 0, len(values))
+	for _, v := range values {
+		t, err := ParseThingType(v)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// JoinThingTypes joins the given thing types into a comma-separated string.
+func JoinThingTypes(types []ThingType) string {
+	strs := make([]string, 0, len(types))
+	for _, t := range types {
+		strs = append(strs, string(t))
+	}
+	return strings.Join(strs, ",")
+}
+
+// SetThingTypes sets the Skip* fields of the config so that only the given
+// thing types are shredded.
+func (cfg *Config) SetThingTypes(types []ThingType) {
+	cfg.SkipComments = true
+	cfg.SkipPosts = true
+	cfg.SkipSavedComments = true
+	cfg.SkipSavedPosts = true
+	cfg.SkipFriends = true
+	cfg.SkipUpvoted = true
+	cfg.SkipDownvoted = true
+	cfg.SkipHidden = true
+	cfg.SkipMessages = true
+	cfg.SkipSubscriptions = true
+	for _, t := range types {
+		switch t {
+		case ThingTypeComments:
+			cfg.SkipComments = false
+		case ThingTypePosts:
+			cfg.SkipPosts = false
+		case ThingTypeSavedComments:
+			cfg.SkipSavedComments = false
+		case ThingTypeSavedPosts:
+			cfg.SkipSavedPosts = false
+		case ThingTypeFriends:
+			cfg.SkipFriends = false
+		case ThingTypeUpvoted:
+			cfg.SkipUpvoted = false
+		case ThingTypeDownvoted:
+			cfg.SkipDownvoted = false
+		case ThingTypeHidden:
+			cfg.SkipHidden = false
+		case ThingTypeMessages:
+			cfg.SkipMessages = false
+		case ThingTypeSubscriptions:
+			cfg.SkipSubscriptions = false
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package shred
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseThingTypes(t *testing.T) {
+	types, err := ParseThingTypes([]string{"comments", "saved-posts"})
+	require.NoError(t, err)
+	require.Equal(t, []ThingType{ThingTypeComments, ThingTypeSavedPosts}, types)
+
+	_, err = ParseThingTypes([]string{"comments", "foo"})
+	require.ErrorContains(t, err, `unknown thing type "foo"`)
+}
+
+func TestSelectThingTypes(t *testing.T) {
+	tests := []struct {
+		name      string
+		chosen    []ThingType
+		ruleTypes []ThingType
+		want      []ThingType
+	}{
+		{
+			name: "defaults",
+			want: DefaultThingTypes,
+		},
+		{
+			name:   "chosen",
+			chosen: []ThingType{ThingTypeComments, ThingTypeUpvoted},
+			want:   []ThingType{ThingTypeComments, ThingTypeUpvoted},
+		},
+		{
+			// Rules can name types which aren't shredded by default.
+			name:      "named by rules",
+			ruleTypes: []ThingType{ThingTypeComments, ThingTypeMessages},
+			want:      []ThingType{ThingTypeComments, ThingTypeMessages},
+		},
+		{
+			name:      "chosen and named by rules",
+			chosen:    []ThingType{ThingTypeComments, ThingTypePosts},
+			ruleTypes: []ThingType{ThingTypeComments, ThingTypeMessages},
+			want:      []ThingType{ThingTypeComments},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				require.Equal(t, tt.want, SelectThingTypes(tt.chosen, tt.ruleTypes))
+			},
+		)
+	}
+}
+
+func TestConfig_SetThingTypes(t *testing.T) {
+	var cfg Config
+	cfg.SetThingTypes([]ThingType{ThingTypeComments, ThingTypeSavedPosts, ThingTypeDownvoted})
+	require.False(t, cfg.SkipComments)
+	require.True(t, cfg.SkipPosts)
+	require.True(t, cfg.SkipSavedComments)
+	require.False(t, cfg.SkipSavedPosts)
+	require.True(t, cfg.SkipFriends)
+	require.True(t, cfg.SkipUpvoted)
+	require.False(t, cfg.SkipDownvoted)
+}
@@ -0,0 +1,132 @@
+package shred
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ccampo133/shreddit-go/internal/reddit"
+)
+
+// verifyComments re-fetches comments which were just shredded to check that
+// the edits and deletions took effect, since Reddit occasionally reports
+// success for operations which didn't stick. Comments which weren't shredded
+// are shredded again and re-checked once, and any still not shredded after
+// that are logged and counted as unverified.
+func (s *Shredder) verifyComments(ctx context.Context, comments []reddit.Comment) error {
+	failed, err := s.unshreddedComments(ctx, comments)
+	if err != nil {
+		return err
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	for _, comment := range failed {
+		s.cfg.Logger.Warn("Comment wasn't shredded; retrying", "permalink", comment.Permalink)
+		if strings.TrimSpace(comment.Body) != strings.TrimSpace(s.cfg.ReplacementComment) {
+			if err := s.client.EditComment(ctx, comment.ID, s.cfg.ReplacementComment); err != nil {
+				return fmt.Errorf("error editing comment: %w", err)
+			}
+		}
+		if !s.cfg.EditOnly {
+			if err := s.client.DeleteComment(ctx, comment.ID); err != nil {
+				return fmt.Errorf("error deleting comment: %w", err)
+			}
+		}
+	}
+	if err := sleep(ctx, s.cfg.Sleep); err != nil {
+		return err
+	}
+	if failed, err = s.unshreddedComments(ctx, failed); err != nil {
+		return err
+	}
+	for _, comment := range failed {
+		s.cfg.Logger.Warn("Failed to verify that comment was shredded", "permalink", comment.Permalink)
+		s.summary.unverify(ThingTypeComments)
+	}
+	return nil
+}
+
+// unshreddedComments returns the current versions of the given comments which
+// haven't been shredded. Comments which no longer exist at all count as
+// shredded.
+func (s *Shredder) unshreddedComments(ctx context.Context, comments []reddit.Comment) ([]reddit.Comment, error) {
+	fullnames := make([]reddit.Fullname, 0, len(comments))
+	for _, comment := range comments {
+		fullnames = append(fullnames, commentFullname(comment))
+	}
+	current, err := s.client.GetThingsByFullname(ctx, fullnames)
+	if err != nil {
+		return nil, fmt.Errorf("error verifying comments: %w", err)
+	}
+	var failed []reddit.Comment
+	for _, comment := range current.Comments {
+		deleted := comment.Author == reddit.DeletedText && comment.Body == reddit.DeletedText
+		edited := strings.TrimSpace(comment.Body) == strings.TrimSpace(s.cfg.ReplacementComment)
+		if deleted || (s.cfg.EditOnly && edited) {
+			continue
+		}
+		failed = append(failed, comment)
+	}
+	return failed, nil
+}
+
+// verifyPosts is like verifyComments, but for posts. Only self posts are
+// edited, so with EditOnly, link posts aren't checked.
+func (s *Shredder) verifyPosts(ctx context.Context, posts []reddit.Post) error {
+	failed, err := s.unshreddedPosts(ctx, posts)
+	if err != nil {
+		return err
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	for _, post := range failed {
+		s.cfg.Logger.Warn("Post wasn't shredded; retrying", "permalink", post.Permalink)
+		if post.IsSelf && strings.TrimSpace(post.Selftext) != strings.TrimSpace(s.cfg.ReplacementComment) {
+			if err := s.client.EditPost(ctx, post.ID, s.cfg.ReplacementComment); err != nil {
+				return fmt.Errorf("error editing post: %w", err)
+			}
+		}
+		if !s.cfg.EditOnly {
+			if err := s.client.DeletePost(ctx, post.ID); err != nil {
+				return fmt.Errorf("error deleting post: %w", err)
+			}
+		}
+	}
+	if err := sleep(ctx, s.cfg.Sleep); err != nil {
+		return err
+	}
+	if failed, err = s.unshreddedPosts(ctx, failed); err != nil {
+		return err
+	}
+	for _, post := range failed {
+		s.cfg.Logger.Warn("Failed to verify that post was shredded", "permalink", post.Permalink)
+		s.summary.unverify(ThingTypePosts)
+	}
+	return nil
+}
+
+// unshreddedPosts returns the current versions of the given posts which
+// haven't been shredded. Posts which no longer exist at all count as
+// shredded.
+func (s *Shredder) unshreddedPosts(ctx context.Context, posts []reddit.Post) ([]reddit.Post, error) {
+	fullnames := make([]reddit.Fullname, 0, len(posts))
+	for _, post := range posts {
+		fullnames = append(fullnames, postFullname(post))
+	}
+	current, err := s.client.GetThingsByFullname(ctx, fullnames)
+	if err != nil {
+		return nil, fmt.Errorf("error verifying posts: %w", err)
+	}
+	var failed []reddit.Post
+	for _, post := range current.Posts {
+		deleted := post.Author == reddit.DeletedText
+		edited := !post.IsSelf || strings.TrimSpace(post.Selftext) == strings.TrimSpace(s.cfg.ReplacementComment)
+		if deleted || (s.cfg.EditOnly && edited) {
+			continue
+		}
+		failed = append(failed, post)
+	}
+	return failed, nil
+}
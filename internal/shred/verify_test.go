@@ -0,0 +1,79 @@
+package shred
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ccampo133/shreddit-go/internal/reddit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShredder_verifyComments(t *testing.T) {
+	var infoCalls, edits, deletes int
+	s := newTestShredder(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/info":
+				infoCalls++
+				// Only the comment which failed is checked again.
+				if infoCalls == 1 {
+					require.Equal(t, "t1_a,t1_b,t1_c", r.URL.Query().Get("id"))
+				} else {
+					require.Equal(t, "t1_b", r.URL.Query().Get("id"))
+				}
+				// "a" was deleted, "b" was only edited, and "c" no longer
+				// exists. Once retried, "b" is deleted too.
+				b := `{"kind": "t1", "data": {"id": "b", "author": "test_username", "body": "[deleted]!"}}`
+				if infoCalls > 1 {
+					b = `{"kind": "t1", "data": {"id": "b", "author": "[deleted]", "body": "[deleted]"}}`
+				}
+				_, _ = w.Write(
+					[]byte(`{"data": {"children": [{"kind": "t1", "data": {"id": "a", "author": "[deleted]", "body": "[deleted]"}}, ` + b + `]}}`),
+				)
+			case "/api/editusertext":
+				require.NoError(t, r.ParseForm())
+				require.Equal(t, "t1_b", r.PostForm.Get("thing_id"))
+				edits++
+				_, _ = w.Write([]byte(`{"jquery": [], "success": true}`))
+			case "/api/del":
+				require.NoError(t, r.ParseForm())
+				require.Equal(t, "t1_b", r.PostForm.Get("id"))
+				deletes++
+				_, _ = w.Write([]byte(`{}`))
+			default:
+				t.Fatalf("unexpected request to %s", r.URL.Path)
+			}
+		},
+		Config{ReplacementComment: "[deleted]!"},
+	)
+	err := s.verifyComments(
+		context.Background(), []reddit.Comment{{ID: "a"}, {ID: "b"}, {ID: "c"}},
+	)
+	require.NoError(t, err)
+	require.Equal(t, 2, infoCalls)
+	// The comment was already edited, so it's only deleted again.
+	require.Equal(t, 0, edits)
+	require.Equal(t, 1, deletes)
+	require.Empty(t, s.Summary().Unverified)
+}
+
+func TestShredder_verifyPosts_Unverified(t *testing.T) {
+	s := newTestShredder(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/info":
+				require.Equal(t, "t3_a", r.URL.Query().Get("id"))
+				// The post is never deleted.
+				_, _ = w.Write([]byte(`{"data": {"children": [{"kind": "t3", "data": {"id": "a", "author": "test_username"}}]}}`))
+			case "/api/del":
+				_, _ = w.Write([]byte(`{}`))
+			default:
+				t.Fatalf("unexpected request to %s", r.URL.Path)
+			}
+		},
+		Config{},
+	)
+	require.NoError(t, s.verifyPosts(context.Background(), []reddit.Post{{ID: "a"}}))
+	require.Equal(t, map[ThingType]int{ThingTypePosts: 1}, s.Summary().Unverified)
+}
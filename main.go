@@ -2,29 +2,58 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/alecthomas/kong"
+	"github.com/ccampo133/shreddit-go/internal/archive"
+	"github.com/ccampo133/shreddit-go/internal/config"
+	"github.com/ccampo133/shreddit-go/internal/gdpr"
 	"github.com/ccampo133/shreddit-go/internal/reddit"
 	"github.com/ccampo133/shreddit-go/internal/shred"
 )
 
 type CLI struct {
-	Username           string           `help:"Reddit username." short:"u" required:"" env:"SHREDDIT_USERNAME"`
-	Password           string           `help:"Reddit password." short:"p" required:"" env:"SHREDDIT_PASSWORD"`
-	ClientID           string           `help:"Reddit client ID." required:"" env:"SHREDDIT_CLIENT_ID"`
-	ClientSecret       string           `help:"Reddit client secret." required:"" env:"SHREDDIT_CLIENT_SECRET"`
+	Config             kong.ConfigFlag  `help:"Path of a YAML config file to read flags, credentials and rules from. Flags and environment variables take precedence over it." short:"c"`
+	Profile            string           `help:"Name of the profile in the config file to shred, instead of the account given by the flags." xor:"profile" env:"SHREDDIT_PROFILE"`
+	AllProfiles        bool             `help:"Shred every profile in the config file, one after another." xor:"profile" env:"SHREDDIT_ALL_PROFILES"`
+	Parallel           bool             `help:"With --all-profiles, shred the profiles in parallel." env:"SHREDDIT_PARALLEL"`
+	Username           string           `help:"Reddit username. Required unless set by the profile." short:"u" env:"SHREDDIT_USERNAME"`
+	Password           string           `help:"Reddit password. Required unless set by the profile." short:"p" env:"SHREDDIT_PASSWORD"`
+	ClientID           string           `help:"Reddit client ID. Required unless set by the profile." env:"SHREDDIT_CLIENT_ID"`
+	ClientSecret       string           `help:"Reddit client secret. Required unless set by the profile." env:"SHREDDIT_CLIENT_SECRET"`
+	TokenFile          string           `help:"Path of a file holding an OAuth2 token to log in with, instead of a username and password. If it doesn't exist yet, you are asked to authorize shreddit in your browser, and the token is saved to it." type:"path" env:"SHREDDIT_TOKEN_FILE"`
+	RedirectURL        string           `help:"Redirect URL of your Reddit app, used to authorize shreddit for --token-file." default:"${redirect_url}" env:"SHREDDIT_REDIRECT_URL"`
+	TotpSecret         string           `help:"Base32 secret of the account's two-factor authentication, if enabled. This is the key shown when setting it up, not a six digit code." env:"SHREDDIT_TOTP_SECRET"`
 	DryRun             bool             `help:"Don't actually remove anything - just log what would be removed." env:"SHREDDIT_DRY_RUN"`
-	ThingTypes         []string         `help:"Thing types to remove. Possible values: posts, comments, friends, saved-posts, saved-comments" `
+	ThingTypes         []string         `help:"Thing types to remove. Possible values: ${thing_types}. Defaults to the types named by the config file's rules, if any, or else ${default_thing_types}." enum:"${thing_types}" env:"SHREDDIT_THING_TYPES"`
 	Before             time.Time        `help:"Remove things before this date." env:"SHREDDIT_BEFORE"`
 	MaxDays            *int             `help:"Remove things older than this many days. Doesn't apply if using 'before'." env:"SHREDDIT_MAX_DAYS"`
 	MaxScore           *int             `help:"Remove things with a karma score less than this." env:"SHREDDIT_MAX_SCORE"`
-	ReplacementComment string           `help:"Comment to replace removed comments with." short:"r" default:"[deleted]" env:"SHREDDIT_REPLACEMENT_COMMENT"`
+	KeepSubreddits     []string         `help:"Never remove things in these subreddits. Case-insensitive, and supports glob patterns (e.g. 'golang*')." env:"SHREDDIT_KEEP_SUBREDDITS"`
+	OnlySubreddits     []string         `help:"Only remove things in these subreddits. Case-insensitive, and supports glob patterns. 'keep-subreddits' takes precedence. Private messages and friends aren't affected by either." env:"SHREDDIT_ONLY_SUBREDDITS"`
+	ExcludePatterns    []string         `help:"Never remove comments whose body, or posts whose title or self text, matches any of these regular expressions." sep:"none" env:"SHREDDIT_EXCLUDE_PATTERNS"`
+	IncludePatterns    []string         `help:"Only remove comments whose body, or posts whose title or self text, matches at least one of these regular expressions. 'exclude-patterns' takes precedence." sep:"none" env:"SHREDDIT_INCLUDE_PATTERNS"`
+	ReplacementComment string           `help:"Text to replace removed comments and self posts with." short:"r" default:"[deleted]" env:"SHREDDIT_REPLACEMENT_COMMENT"`
 	UserAgent          string           `help:"Reddit user agent." default:"shreddit-go" env:"SHREDDIT_USER_AGENT"`
-	GdprExportDir      string           `help:"The path of the directory of the unzipped GDPR export data. If set, will use the GDPR export data instead of Reddit's APIs for discovering your data." env:"SHREDDIT_GDPR_EXPORT_DIR"`
-	EditOnly           bool             `help:"Only edit comments, don't remove them." env:"SHREDDIT_EDIT_ONLY"`
-	Sleep              time.Duration    `help:"Time to sleep between requests." env:"SHREDDIT_SLEEP"`
+	GdprExportDir      string           `help:"The path of the directory of the unzipped GDPR export data. If set, will use the GDPR export data instead of Reddit's APIs for discovering your data." xor:"discovery" env:"SHREDDIT_GDPR_EXPORT_DIR"`
+	Sweep              bool             `help:"Discover your data by sweeping every sort order and time window of Reddit's listings, which can find things beyond the roughly 1000 returned by the default listing. Much slower than the default." xor:"discovery" env:"SHREDDIT_SWEEP"`
+	Verify             bool             `help:"After shredding each page of comments and posts, re-fetch them to check that the edits and deletions took effect, and retry any that didn't." env:"SHREDDIT_VERIFY"`
+	EditOnly           bool             `help:"Only edit comments and self posts, don't remove them." env:"SHREDDIT_EDIT_ONLY"`
+	Sleep              time.Duration    `help:"Extra time to sleep between things, on top of the automatic pacing of requests according to Reddit's rate limits. Usually unnecessary." env:"SHREDDIT_SLEEP"`
+	StateFile          string           `help:"Path of a file to record progress in, so that an interrupted run can be resumed with --resume. Removed once a run completes." type:"path" env:"SHREDDIT_STATE_FILE"`
+	Resume             bool             `help:"Resume an interrupted run from the state file, skipping things which were already shredded. Requires --state-file." env:"SHREDDIT_RESUME"`
+	Archive            string           `help:"Path of a file to archive comments and posts to before they are shredded, including during a dry run. The format is determined by the extension: .jsonl for JSON Lines, or .csv for CSV. Appended to if it already exists." type:"path" env:"SHREDDIT_ARCHIVE"`
 	Version            kong.VersionFlag `name:"version" short:"v" help:"Print version information and quit"`
 }
 
@@ -41,47 +70,362 @@ func main() {
 		kong.Name("shreddit"),
 		kong.Description("Overwrite and delete your Reddit account history."),
 		kong.UsageOnError(),
+		kong.Configuration(config.Resolver),
 		kong.ConfigureHelp(
 			kong.HelpOptions{
 				Compact: true,
 			},
 		),
 		kong.Vars{
-			"version": version,
+			"version":             version,
+			"thing_types":         shred.JoinThingTypes(shred.ThingTypes),
+			"default_thing_types": shred.JoinThingTypes(shred.DefaultThingTypes),
+			"redirect_url":        reddit.DefaultRedirectURL,
 		},
 	)
 	err := ctx.Run()
 	ctx.FatalIfErrorf(err)
 }
 
+// account is a Reddit account to shred.
+type account struct {
+	// profile is the name of the account's profile in the config file, or
+	// empty if profiles aren't being used.
+	profile string
+	reddit  reddit.Config
+	rules   config.Rules
+}
+
 func (cli *CLI) Run() error {
-	ctx := context.Background()
-	redditCfg := reddit.Config{
-		ClientID:     cli.ClientID,
-		ClientSecret: cli.ClientSecret,
-		Username:     cli.Username,
-		Password:     cli.Password,
-		UserAgent:    cli.UserAgent,
-	}
-	client, err := reddit.NewClient(ctx, redditCfg)
+	// Stop gracefully on SIGINT or SIGTERM. Once the first signal has been
+	// received, stop listening, so that a second one kills the process.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+	var file *config.File
+	if cli.Config != "" {
+		var err error
+		if file, err = config.Load(string(cli.Config)); err != nil {
+			return err
+		}
+	}
+	accounts, err := cli.accounts(file)
 	if err != nil {
-		return fmt.Errorf("error creating Reddit client: %w", err)
+		return err
+	}
+	if cli.Resume && cli.StateFile == "" {
+		return errors.New("--resume requires --state-file")
+	}
+	if cli.Parallel && !cli.AllProfiles {
+		return errors.New("--parallel requires --all-profiles")
+	}
+	if len(accounts) > 1 && cli.GdprExportDir != "" {
+		return errors.New("a GDPR export can't be used with more than one profile")
+	}
+	// Without --thing-types, the types are chosen per account, since they
+	// depend on the account's rules.
+	var thingTypes []shred.ThingType
+	if len(cli.ThingTypes) > 0 {
+		if thingTypes, err = shred.ParseThingTypes(cli.ThingTypes); err != nil {
+			return fmt.Errorf("invalid thing types: %w", err)
+		}
+	}
+	// Authorize any accounts which need it first, one at a time, since it's
+	// interactive and they all use the same redirect URL.
+	for _, acct := range accounts {
+		if err := cli.authorize(ctx, acct); err != nil {
+			return err
+		}
 	}
-	// TODO: check thing types to determine skip bools
 	cfg := shred.Config{
-		Username:           cli.Username,
 		DryRun:             cli.DryRun,
 		EditOnly:           cli.EditOnly,
+		Verify:             cli.Verify,
 		Before:             cli.Before,
 		MaxScore:           cli.MaxScore,
 		MaxDays:            cli.MaxDays,
 		ReplacementComment: cli.ReplacementComment,
 		Sleep:              cli.Sleep,
-		// TODO: skip comments/posts/saved
+		KeepSubreddits:     cli.KeepSubreddits,
+		OnlySubreddits:     cli.OnlySubreddits,
+	}
+	if cfg.ExcludePatterns, err = shred.CompilePatterns(cli.ExcludePatterns); err != nil {
+		return fmt.Errorf("invalid exclude patterns: %w", err)
+	}
+	if cfg.IncludePatterns, err = shred.CompilePatterns(cli.IncludePatterns); err != nil {
+		return fmt.Errorf("invalid include patterns: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+	)
+	run := func(acct account) {
+		defer wg.Done()
+		if err := cli.shred(ctx, cfg, thingTypes, acct); err != nil {
+			if acct.profile != "" {
+				err = fmt.Errorf("profile %q: %w", acct.profile, err)
+			}
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}
+	}
+	for _, acct := range accounts {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		if cli.Parallel {
+			go run(acct)
+		} else {
+			run(acct)
+		}
+	}
+	wg.Wait()
+	err = errors.Join(errs...)
+	if errors.Is(err, context.Canceled) || (err == nil && ctx.Err() != nil) {
+		if cli.DryRun || cli.StateFile == "" {
+			return errors.New("interrupted before finishing")
+		}
+		return errors.New("interrupted before finishing; run again with --resume to continue")
+	}
+	return err
+}
+
+// accounts returns the accounts to shred: those of the selected profiles, or
+// else the one given by the flags.
+func (cli *CLI) accounts(file *config.File) ([]account, error) {
+	base := account{
+		reddit: reddit.Config{
+			ClientID:     cli.ClientID,
+			ClientSecret: cli.ClientSecret,
+			Username:     cli.Username,
+			Password:     cli.Password,
+			UserAgent:    cli.UserAgent,
+			TOTPSecret:   cli.TotpSecret,
+			TokenFile:    cli.TokenFile,
+		},
+	}
+	if file != nil {
+		base.rules = file.Rules
+	}
+	var names []string
+	switch {
+	case cli.Profile != "":
+		names = []string{cli.Profile}
+	case cli.AllProfiles:
+		if file == nil || len(file.Profiles) == 0 {
+			return nil, errors.New("--all-profiles requires a config file with profiles")
+		}
+		names = file.ProfileNames()
+	default:
+		return []account{base}, base.validate()
+	}
+	accounts := make([]account, 0, len(names))
+	for _, name := range names {
+		var profile config.Profile
+		var ok bool
+		if file != nil {
+			profile, ok = file.Profiles[name]
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q", name)
+		}
+		acct := base
+		acct.profile = name
+		creds := []struct {
+			secret config.Secret
+			value  *string
+		}{
+			{profile.Credentials.Username, &acct.reddit.Username},
+			{profile.Credentials.Password, &acct.reddit.Password},
+			{profile.Credentials.ClientID, &acct.reddit.ClientID},
+			{profile.Credentials.ClientSecret, &acct.reddit.ClientSecret},
+			{profile.Credentials.TOTPSecret, &acct.reddit.TOTPSecret},
+		}
+		for _, cred := range creds {
+			var err error
+			if *cred.value, err = cred.secret.ResolveOr(*cred.value); err != nil {
+				return nil, fmt.Errorf("profile %q: %w", name, err)
+			}
+		}
+		switch {
+		case profile.TokenFile != "":
+			acct.reddit.TokenFile = kong.ExpandPath(profile.TokenFile)
+		case acct.reddit.TokenFile != "":
+			acct.reddit.TokenFile = profilePath(acct.reddit.TokenFile, name)
+		}
+		if profile.UserAgent != "" {
+			acct.reddit.UserAgent = profile.UserAgent
+		}
+		if len(profile.Rules) > 0 {
+			acct.rules = profile.Rules
+		}
+		if err := acct.validate(); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, acct)
+	}
+	return accounts, nil
+}
+
+// validate checks that the account has all of its credentials. These can't be
+// required by the flags themselves, since profiles can provide them instead.
+// With a token file, only the client ID is needed: the username is looked up,
+// and installed apps have no client secret.
+func (acct account) validate() error {
+	required := map[string]string{"--client-id": acct.reddit.ClientID}
+	if acct.reddit.TokenFile == "" {
+		required["--username"] = acct.reddit.Username
+		required["--password"] = acct.reddit.Password
+		required["--client-secret"] = acct.reddit.ClientSecret
+	}
+	var missing []string
+	for flag, value := range required {
+		if value == "" {
+			missing = append(missing, flag)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	slices.Sort(missing)
+	if acct.profile != "" {
+		return fmt.Errorf("profile %q is missing credentials: %s", acct.profile, strings.Join(missing, ", "))
+	}
+	return fmt.Errorf("missing credentials: %s", strings.Join(missing, ", "))
+}
+
+// authorize gets a token for the account with the authorization code flow and
+// saves it, if the account uses a token file which doesn't exist yet.
+func (cli *CLI) authorize(ctx context.Context, acct account) error {
+	path := acct.reddit.TokenFile
+	if path == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		// Any other problems with the file are reported when it's loaded.
+		return nil
+	}
+	tok, err := reddit.Authorize(
+		ctx, acct.reddit, cli.RedirectURL, func(authURL string) {
+			account := "your account"
+			if acct.profile != "" {
+				account = fmt.Sprintf("profile %q", acct.profile)
+			}
+			fmt.Fprintf(os.Stderr, "To authorize shreddit for %s, open this URL in your browser:\n\n  %s\n\n", account, authURL)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("error authorizing: %w", err)
+	}
+	if err := reddit.SaveToken(path, tok); err != nil {
+		return err
+	}
+	slog.Info("Saved token", "path", path, "profile", acct.profile)
+	return nil
+}
+
+// shred shreds a single account, using cfg as the base config.
+func (cli *CLI) shred(ctx context.Context, cfg shred.Config, thingTypes []shred.ThingType, acct account) error {
+	logger := slog.Default()
+	if acct.profile != "" {
+		logger = logger.With("profile", acct.profile)
+	}
+	cfg.Logger = logger
+	var ruleTypes []shred.ThingType
+	if len(acct.rules) > 0 {
+		rules, types, err := acct.rules.Filter(time.Now())
+		if err != nil {
+			return fmt.Errorf("invalid config file: %w", err)
+		}
+		cfg.Filters = append(slices.Clone(cfg.Filters), rules)
+		ruleTypes = types
+	}
+	thingTypes = shred.SelectThingTypes(thingTypes, ruleTypes)
+	cfg.SetThingTypes(thingTypes)
+	client, err := reddit.NewClient(ctx, acct.reddit)
+	if err != nil {
+		return fmt.Errorf("error creating Reddit client: %w", err)
+	}
+	if acct.reddit.Username == "" {
+		me, err := client.GetMe(ctx)
+		if err != nil {
+			return err
+		}
+		acct.reddit.Username = me.Name
+	}
+	cfg.Username = acct.reddit.Username
+	if cli.GdprExportDir != "" {
+		export, err := gdpr.Load(cli.GdprExportDir)
+		if err != nil {
+			return fmt.Errorf("error loading GDPR export: %w", err)
+		}
+		logger.Info(
+			"Loaded GDPR export",
+			"dir", cli.GdprExportDir,
+			"comments", len(export.Comments),
+			"posts", len(export.Posts),
+			"savedComments", len(export.SavedComments),
+			"savedPosts", len(export.SavedPosts),
+		)
+		cfg.Discoverer = shred.NewExportDiscoverer(client, export)
+	}
+	if cli.Sweep {
+		cfg.Discoverer = shred.NewSweepDiscoverer(client, acct.reddit.Username, logger)
+	}
+	logger.Info(
+		"Starting shreddit",
+		"username", acct.reddit.Username,
+		"thingTypes", shred.JoinThingTypes(thingTypes),
+		"dryRun", cli.DryRun,
+		"editOnly", cli.EditOnly,
+	)
+	// Nothing is done during a dry run, so there is no progress to record.
+	if !cli.DryRun && cli.StateFile != "" {
+		stateFile := profilePath(cli.StateFile, acct.profile)
+		cfg.Checkpoint = shred.NewCheckpoint(stateFile)
+		if cli.Resume {
+			cfg.Checkpoint, err = shred.LoadCheckpoint(stateFile)
+			if err != nil {
+				return fmt.Errorf("error loading state file: %w", err)
+			}
+			logger.Info("Resuming from state file", "path", stateFile, "done", cfg.Checkpoint.Len())
+		}
+		defer cfg.Checkpoint.Close()
+	}
+	if cli.Archive != "" {
+		cfg.Archive, err = archive.Open(profilePath(cli.Archive, acct.profile))
+		if err != nil {
+			return err
+		}
+		defer cfg.Archive.Close()
 	}
 	shredder := shred.NewShredder(client, cfg)
-	if err := shredder.Shred(); err != nil {
+	err = shredder.Shred(ctx)
+	logger.Info("Finished shredding", "summary", shredder.Summary())
+	if err != nil {
 		return fmt.Errorf("error shredding: %w", err)
 	}
+	if cfg.Checkpoint != nil {
+		if err := cfg.Checkpoint.Remove(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// profilePath returns the path of a per-account file, such as the state file,
+// for the given profile, by inserting the profile's name before the extension
+// (e.g. "state.alt.json"). Without a profile, the path is returned unchanged.
+func profilePath(path, profile string) string {
+	if profile == "" {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}